@@ -0,0 +1,110 @@
+package runeset
+
+import (
+	"slices"
+)
+
+func (set Set) Union(other Set) Set {
+	switch {
+	case other.IsEmpty() || set.IsFull():
+		return set
+	case set.IsEmpty() || other.IsFull():
+		return other
+	}
+	out := make([]Pair, 0, set.Len()+other.Len())
+	return shareIfEqual(unionSweep(out, set, other), set, other)
+}
+
+func (set Set) Intersect(other Set) Set {
+	switch {
+	case set.IsEmpty() || other.IsFull():
+		return set
+	case other.IsEmpty() || set.IsFull():
+		return other
+	}
+	out := make([]Pair, 0, set.Len()+other.Len())
+	return shareIfEqual(intersectSweep(out, set, other), set, other)
+}
+
+func (set Set) Difference(other Set) Set {
+	switch {
+	case set.IsEmpty() || other.IsEmpty():
+		return set
+	case other.IsFull():
+		return Empty()
+	}
+	out := make([]Pair, 0, set.Len()+other.Len())
+	return shareIfEqual(differenceSweep(out, set, other), set)
+}
+
+func (set Set) SymmetricDifference(other Set) Set {
+	switch {
+	case other.IsEmpty():
+		return set
+	case set.IsEmpty():
+		return other
+	}
+	out := make([]Pair, 0, set.Len()+other.Len())
+	return shareIfEqual(symmetricSweep(out, set, other), set, other)
+}
+
+func (set Set) Complement() Set {
+	switch {
+	case set.IsEmpty():
+		return Full()
+	case set.IsFull():
+		return Empty()
+	}
+	out := make([]Pair, 0, set.Len()+1)
+	return makeSet(complementSweep(out, set))
+}
+
+func Union(sets ...Set) Set {
+	inputs := make([]Source, 0, len(sets))
+	var last Set
+	for _, set := range sets {
+		if set.IsFull() {
+			return set
+		}
+		if !set.IsEmpty() {
+			inputs = append(inputs, set)
+			last = set
+		}
+	}
+	switch len(inputs) {
+	case 0:
+		return Empty()
+	case 1:
+		return last
+	}
+	out := make([]Pair, 0, totalLen(inputs))
+	return shareIfEqual(unionAll(out, inputs), sets...)
+}
+
+// Intersection returns the runes common to all of sets.  The intersection of
+// no sets at all is Full.
+func Intersection(sets ...Set) Set {
+	if len(sets) <= 0 {
+		return Full()
+	}
+	acc := sets[0]
+	for _, set := range sets[1:] {
+		if acc.IsEmpty() {
+			break
+		}
+		acc = acc.Intersect(set)
+	}
+	return acc
+}
+
+// shareIfEqual wraps list in a Set, unless one of the candidates already
+// holds the same pairs, in which case that candidate is returned instead so
+// that the two share a backing array.
+func shareIfEqual(list []Pair, candidates ...Set) Set {
+	for _, set := range candidates {
+		if plain, ok := set.plainList(); ok && slices.Equal(plain, list) {
+			return set
+		}
+	}
+	return makeSet(list)
+}
@@ -0,0 +1,46 @@
+package runeset
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSet_Algebra(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for iter := 0; iter < 2000; iter++ {
+		a := Make(randomPairs(rng))
+		b := Make(randomPairs(rng))
+		c := Make(randomPairs(rng))
+
+		check := func(name string, expect *Builder, actual Set) {
+			t.Helper()
+			if actual.String() != expect.String() {
+				t.Fatalf("%s(%v, %v):\n\texpect: %v\n\tactual: %v", name, a, b, expect, actual)
+			}
+		}
+
+		check("Union", a.Builder().Add(b), a.Union(b))
+		check("Intersect", a.Builder().Intersect(b), a.Intersect(b))
+		check("Difference", a.Builder().Remove(b), a.Difference(b))
+		check("SymmetricDifference", a.Builder().Add(b).Remove(a.Builder().Intersect(b)), a.SymmetricDifference(b))
+		check("Complement", a.Builder().Negate(), a.Complement())
+		check("UnionN", a.Builder().Add(b, c), Union(a, b, c))
+		check("IntersectionN", a.Builder().Intersect(b, c), Intersection(a, b, c))
+	}
+
+	if actual := Intersection(); !actual.IsFull() {
+		t.Errorf("Intersection(): expected Full, got %v", actual)
+	}
+	if actual := Union(); !actual.IsEmpty() {
+		t.Errorf("Union(): expected Empty, got %v", actual)
+	}
+
+	a := Make(Pair{'a', 'z'})
+	b := Make(Pair{'c', 'f'})
+	if u := a.Union(b); !u.Equal(a) {
+		t.Errorf("Union: expected %v, got %v", a, u)
+	}
+	if i := a.Intersect(b); !i.Equal(b) {
+		t.Errorf("Intersect: expected %v, got %v", b, i)
+	}
+}
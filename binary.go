@@ -0,0 +1,170 @@
+package runeset
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"unicode"
+)
+
+// The binary form of a Set is:
+//
+//	version  1 byte, currently 1
+//	flags    1 byte; bit 0 set means a checksum follows the pairs
+//	count    uvarint, the number of pairs
+//	pairs    count times: uvarint gap, uvarint length
+//	checksum 4 bytes, little-endian CRC-32 (IEEE) of all preceding bytes
+//
+// For each pair, length is Hi-Lo.  For the first pair, gap is Lo; for the
+// rest, gap is Lo-(prev.Hi+2), the number of runes strictly between the
+// pairs less one, so that every decodable input is canonical.  Varints must
+// be minimally encoded.
+
+const (
+	binaryVersion      = 1
+	binaryFlagChecksum = 1 << 0
+	binaryHeaderLen    = 2
+	binaryChecksumLen  = 4
+)
+
+func (set Set) AppendBinary(out []byte) ([]byte, error) {
+	return set.appendBinary(out, 0), nil
+}
+
+// AppendBinaryChecksum is like AppendBinary, but adds a checksum which
+// UnmarshalBinary verifies.
+func (set Set) AppendBinaryChecksum(out []byte) ([]byte, error) {
+	return set.appendBinary(out, binaryFlagChecksum), nil
+}
+
+func (set Set) appendBinary(out []byte, flags byte) []byte {
+	start := len(out)
+	out = append(out, binaryVersion, flags)
+	n := set.Len()
+	out = binary.AppendUvarint(out, uint64(n))
+	next := rune(0)
+	for i := uint(0); i < n; i++ {
+		pair := set.At(i)
+		out = binary.AppendUvarint(out, uint64(pair.Lo-next))
+		out = binary.AppendUvarint(out, uint64(pair.Hi-pair.Lo))
+		next = pair.Hi + 2
+	}
+	if (flags & binaryFlagChecksum) != 0 {
+		out = binary.LittleEndian.AppendUint32(out, crc32.ChecksumIEEE(out[start:]))
+	}
+	return out
+}
+
+func (set Set) MarshalBinary() ([]byte, error) {
+	return set.AppendBinary(nil)
+}
+
+// UnmarshalBinary decodes the binary form, with or without a checksum.  Any
+// error is a MalformedError.
+func (set *Set) UnmarshalBinary(data []byte) error {
+	if len(data) < binaryHeaderLen {
+		return MalformedError{Offset: len(data), Reason: "truncated header"}
+	}
+	if version := data[0]; version != binaryVersion {
+		return MalformedError{Offset: 0, Reason: fmt.Sprintf("unsupported version %d", version)}
+	}
+	flags := data[1]
+	if (flags &^ binaryFlagChecksum) != 0 {
+		return MalformedError{Offset: 1, Reason: fmt.Sprintf("unknown flags %#02x", flags)}
+	}
+	if (flags & binaryFlagChecksum) != 0 {
+		end := len(data) - binaryChecksumLen
+		if end < binaryHeaderLen {
+			return MalformedError{Offset: len(data), Reason: "truncated checksum"}
+		}
+		if binary.LittleEndian.Uint32(data[end:]) != crc32.ChecksumIEEE(data[:end]) {
+			return MalformedError{Offset: end, Reason: "checksum mismatch"}
+		}
+		data = data[:end]
+	}
+
+	d := binaryDecoder{data: data, pos: binaryHeaderLen}
+	count, err := d.uvarint("pair count")
+	if err != nil {
+		return err
+	}
+	// every pair takes at least two bytes
+	if count > uint64(len(data)-d.pos)/2 {
+		return MalformedError{Offset: binaryHeaderLen, Reason: fmt.Sprintf("pair count %d exceeds the data", count)}
+	}
+
+	list := make([]Pair, 0, count)
+	next := uint64(0)
+	for i := uint64(0); i < count; i++ {
+		offset := d.pos
+		gap, err := d.uvarint("gap")
+		if err != nil {
+			return err
+		}
+		length, err := d.uvarint("length")
+		if err != nil {
+			return err
+		}
+		lo := next + gap
+		hi := lo + length
+		if lo < next || hi < lo || hi > unicode.MaxRune {
+			return MalformedError{Offset: offset, Reason: fmt.Sprintf("pair %d extends beyond U+10FFFF", i), Err: ErrInvalidRune}
+		}
+		list = append(list, Pair{rune(lo), rune(hi)})
+		next = hi + 2
+	}
+	if d.pos != len(data) {
+		return MalformedError{Offset: d.pos, Reason: "trailing data"}
+	}
+	*set = makeSet(list)
+	return nil
+}
+
+type binaryDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *binaryDecoder) uvarint(what string) (uint64, error) {
+	v, n := binary.Uvarint(d.data[d.pos:])
+	var scratch [binary.MaxVarintLen64]byte
+	switch {
+	case n == 0:
+		return 0, MalformedError{Offset: d.pos, Reason: "truncated " + what}
+	case n < 0:
+		return 0, MalformedError{Offset: d.pos, Reason: what + " overflows 64 bits"}
+	case n != binary.PutUvarint(scratch[:], v):
+		return 0, MalformedError{Offset: d.pos, Reason: what + " is not minimally encoded"}
+	}
+	d.pos += n
+	return v, nil
+}
+
+var gBase64 = base64.RawURLEncoding
+
+// Base64 returns the binary form of the Set with a checksum, in unpadded
+// URL-safe base64, for use in URLs and query strings.
+func (set Set) Base64() string {
+	data, _ := set.AppendBinaryChecksum(nil)
+	return gBase64.EncodeToString(data)
+}
+
+// ParseBase64 decodes the output of Set.Base64.
+func ParseBase64(str string) (Set, error) {
+	data, err := gBase64.DecodeString(str)
+	if err != nil {
+		return Empty(), MalformedError{Offset: 0, Reason: "invalid base64", Err: err}
+	}
+	var set Set
+	if err := set.UnmarshalBinary(data); err != nil {
+		return Empty(), err
+	}
+	return set, nil
+}
+
+var (
+	_ encoding.BinaryMarshaler   = Set{}
+	_ encoding.BinaryUnmarshaler = (*Set)(nil)
+)
@@ -2,12 +2,13 @@ package runeset
 
 import (
 	"fmt"
-	"unicode"
 )
 
 type Builder struct {
-	l []Pair
-	s [16]Pair
+	l       []Pair
+	s       [16]Pair
+	err     error
+	collect bool
 }
 
 func NewBuilder() *Builder {
@@ -21,12 +22,44 @@ func (b *Builder) assertNotNil() {
 	}
 }
 
+// Reset empties the Builder and forgets any collected error.  It does not
+// change whether errors are being collected.
 func (b *Builder) Reset() *Builder {
 	b.l = b.s[:0]
 	clear(b.s[:])
+	b.err = nil
 	return b
 }
 
+// CollectErrors switches the Builder from panicking on invalid input to
+// skipping it.  The first such error is reported by Err and BuildErr.
+func (b *Builder) CollectErrors() *Builder {
+	b.assertNotNil()
+	b.collect = true
+	return b
+}
+
+func (b *Builder) Err() error {
+	if b == nil {
+		return nil
+	}
+	return b.err
+}
+
+func (b *Builder) check(pair Pair) bool {
+	err := pair.Validate()
+	if err == nil {
+		return true
+	}
+	if !b.collect {
+		panic(fmt.Errorf("BUG: %w", err))
+	}
+	if b.err == nil {
+		b.err = err
+	}
+	return false
+}
+
 func (b *Builder) Len() uint {
 	if b == nil {
 		return 0
@@ -71,20 +104,18 @@ func (b *Builder) String() string {
 
 func (b *Builder) Negate() *Builder {
 	b.assertNotNil()
-	in := b.l
-	n := uint(len(in))
-	out := make([]Pair, n+1)
-	out[0].Lo = 0
-	if n > 0 {
-		out[0].Hi = in[0].Lo - 1
-		for i := uint(1); i < n; i++ {
-			out[i].Lo = in[i-1].Hi + 1
-			out[i].Hi = in[i].Lo - 1
-		}
-		out[n].Lo = in[n-1].Hi + 1
-	}
-	out[n].Hi = unicode.MaxRune
-	b.update(out)
+	out := make([]Pair, 0, len(b.l)+1)
+	b.commit(complementSweep(out, PairList(b.l)))
+	return b
+}
+
+// NegateWithin is like Negate, but complements relative to universe instead
+// of Full, e.g. ScalarValues to keep surrogates out of the result.
+func (b *Builder) NegateWithin(universe Source) *Builder {
+	b.assertNotNil()
+	within := b.normalize(universe)
+	out := make([]Pair, 0, within.Len()+uint(len(b.l)))
+	b.commit(differenceSweep(out, within, PairList(b.l)))
 	return b
 }
 
@@ -94,16 +125,13 @@ func (b *Builder) Add(sources ...Source) *Builder {
 		return b
 	}
 
-	list := cloneList(b.l)
+	inputs := make([]Source, 0, len(sources)+1)
+	inputs = append(inputs, PairList(b.l))
 	for _, src := range sources {
-		srcLen := src.Len()
-		for i := uint(0); i < srcLen; i++ {
-			pair := src.At(i)
-			pair.AssertValid()
-			list = append(list, pair)
-		}
+		inputs = append(inputs, b.normalize(src))
 	}
-	b.update(list)
+	out := make([]Pair, 0, totalLen(inputs))
+	b.commit(unionAll(out, inputs))
 	return b
 }
 
@@ -129,27 +157,18 @@ func (b *Builder) Remove(sources ...Source) *Builder {
 		return b
 	}
 
-	list := b.l
-	k := 2 * uint(len(list))
-	in := make([]Pair, 0, k)
-	out := make([]Pair, 0, k)
-
-	in = append(in, list...)
-	fn := func(pair Pair) { out = append(out, pair) }
-
+	inputs := make([]Source, 0, len(sources))
 	for _, src := range sources {
-		srcLen := src.Len()
-		for i := uint(0); i < srcLen; i++ {
-			remove := src.At(i)
-			remove.AssertValid()
-			for _, pair := range in {
-				segment(pair, remove, fn, nil, fn)
-			}
-			in, out = out, in[:0]
-		}
+		inputs = append(inputs, b.normalize(src))
 	}
-
-	b.update(in)
+	var remove Source
+	if len(inputs) == 1 {
+		remove = inputs[0]
+	} else {
+		remove = PairList(unionAll(make([]Pair, 0, totalLen(inputs)), inputs))
+	}
+	out := make([]Pair, 0, uint(len(b.l))+remove.Len())
+	b.commit(differenceSweep(out, PairList(b.l), remove))
 	return b
 }
 
@@ -176,26 +195,12 @@ func (b *Builder) Intersect(sources ...Source) *Builder {
 	}
 
 	list := b.l
-	k := 2 * uint(len(list))
-	in := make([]Pair, 0, k)
-	out := make([]Pair, 0, k)
-
-	in = append(in, list...)
-	fn := func(pair Pair) { out = append(out, pair) }
-
 	for _, src := range sources {
-		srcLen := src.Len()
-		for i := uint(0); i < srcLen; i++ {
-			keep := src.At(i)
-			keep.AssertValid()
-			for _, pair := range in {
-				segment(pair, keep, nil, fn, nil)
-			}
-		}
-		in, out = out, in[:0]
+		keep := b.normalize(src)
+		out := make([]Pair, 0, uint(len(list))+keep.Len())
+		list = intersectSweep(out, PairList(list), keep)
 	}
-
-	b.update(in)
+	b.commit(list)
 	return b
 }
 
@@ -215,76 +220,58 @@ func (b *Builder) IntersectRune(runes ...rune) *Builder {
 	return b.Intersect(RuneList(runes))
 }
 
-func (b *Builder) update(list []Pair) {
-	// phase one: remove invalid pairs
-	i := uint(0)
-	for i < uint(len(list)) {
-		pair := list[i]
-		if !pair.IsValid() {
-			list = splice(list, i, i+1)
-			continue
-		}
-		i++
-	}
-
-	// verify
-	for i = 0; i < uint(len(list)); i++ {
-		list[i].AssertValid()
+// normalize returns src if it is already canonical.  Otherwise, it validates
+// each pair of src and returns the sorted and merged list of valid pairs.
+func (b *Builder) normalize(src Source) Source {
+	if isCanonical(src) {
+		return src
 	}
-
-	// phase two: sort
-	PairList(list).Sort()
-
-	// phase three: merge overlapping/adjacent
-	i = 1
-	for i < uint(len(list)) {
-		prev := list[i-1]
-		curr := list[i]
-		if prev.Hi == unicode.MaxRune {
-			n := uint(len(list))
-			list = splice(list, i, n)
+	n := src.Len()
+	list := make([]Pair, 0, n)
+	for i := uint(0); i < n; i++ {
+		pair := sourceAt(src, i)
+		if !b.check(pair) {
 			continue
 		}
-		if prev.Hi >= curr.Hi {
-			list = splice(list, i, i+1)
-			continue
-		}
-		if (prev.Hi + 1) >= curr.Lo {
-			prev.Hi = curr.Hi
-			list[i-1] = prev
-			list = splice(list, i, i+1)
-			continue
-		}
-		i++
+		list = append(list, pair)
 	}
+	return PairList(normalizeList(list))
+}
 
-	// verify
-	for i = 1; i < uint(len(list)); i++ {
-		curr := list[i]
-		prev := list[i-1]
-		if prev.Lo >= curr.Lo {
-			panic(fmt.Errorf("BUG: [%d] %v >= [%d] %v", i-1, prev, i, curr))
-		}
-		if prev.Hi >= (curr.Lo + 1) {
-			panic(fmt.Errorf("BUG: [%d] %v touches [%d] %v and should have been merged with it", i-1, prev, i, curr))
-		}
+// commit replaces the contents of the Builder with list, which must be
+// canonical and must not be referenced by anything else.
+func (b *Builder) commit(list []Pair) {
+	verifyList(list)
+	if len(list) <= len(b.s) {
+		b.l = append(b.s[:0], list...)
+		return
 	}
-
-	// commit
-	b.l = cloneListInto(b.s[:], list)
+	b.l = list
 }
 
 func (b *Builder) Clone() *Builder {
-	return NewBuilder().Add(b)
+	out := NewBuilder()
+	out.err = b.err
+	out.collect = b.collect
+	return out.Add(b)
 }
 
 func (b *Builder) Build() Set {
-	list := cloneList(b.l)
-	return Set{list}
+	return makeSet(cloneList(b.l))
 }
 
+func (b *Builder) BuildErr() (Set, error) {
+	if err := b.Err(); err != nil {
+		return Empty(), err
+	}
+	return b.Build(), nil
+}
+
+func (*Builder) canonical() {}
+
 var (
-	_ Source       = (*Builder)(nil)
-	_ Appender     = (*Builder)(nil)
-	_ fmt.Stringer = (*Builder)(nil)
+	_ Source          = (*Builder)(nil)
+	_ alwaysCanonical = (*Builder)(nil)
+	_ Appender        = (*Builder)(nil)
+	_ fmt.Stringer    = (*Builder)(nil)
 )
@@ -1,17 +1,21 @@
 package runeset
 
 import (
-	"fmt"
+	"sync"
 	"unicode"
 )
 
-var classMap map[string]Set
+// The default registry is populated on first use, and each of its classes
+// is built on first lookup, so that programs which never look up a class
+// by name pay nothing for the tables.
+var gDefaultRegistry = sync.OnceValue(newDefaultRegistry)
 
 func ForClass(className string) Set {
-	if set, found := classMap[className]; found {
-		return set
-	}
-	panic(fmt.Errorf("unknown character class %q", className))
+	return DefaultRegistry().ForClass(className)
+}
+
+func LookupClass(className string) (Set, bool) {
+	return DefaultRegistry().LookupClass(className)
 }
 
 func ForTable(table *unicode.RangeTable) Set {
@@ -49,47 +53,50 @@ func ForTable(table *unicode.RangeTable) Set {
 	return b.Reset().Add(out).Build()
 }
 
-func init() {
-	classMap = make(map[string]Set, 64)
-	for name, table := range unicode.Categories {
-		classMap[name] = ForTable(table)
-	}
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	def := r.registerLazy
+	alias := r.MustRegisterAlias
+	get := r.ForClass
+
+	registerUnicode(r)
 
-	classMap["ascii"] = Make(Pair{0x00, 0x7f})
-	classMap["ascii.graph"] = Make(Pair{0x21, 0x7e})
-	classMap["ascii.print"] = Make(Pair{0x20, 0x7e})
-	classMap["ascii.cntrl"] = Make(Pair{0x00, 0x1f}, Pair{0x7f, 0x7f})
-	classMap["ascii.upper"] = Make(Pair{'A', 'Z'})
-	classMap["ascii.lower"] = Make(Pair{'a', 'z'})
-	classMap["ascii.alpha"] = Make(Pair{'A', 'Z'}, Pair{'a', 'z'})
-	classMap["ascii.digit"] = Make(Pair{'0', '9'})
-	classMap["ascii.bdigit"] = Make(Pair{'0', '1'})
-	classMap["ascii.odigit"] = Make(Pair{'0', '7'})
-	classMap["ascii.xdigit"] = Make(Pair{'0', '9'}, Pair{'A', 'F'}, Pair{'a', 'f'})
-	classMap["ascii.alnum"] = Make(Pair{'0', '9'}, Pair{'A', 'Z'}, Pair{'a', 'z'})
-	classMap["ascii.word"] = Make(ForClass("ascii.alnum"), Pair{'_', '_'})
-	classMap["ascii.punct"] = NewBuilder().Add(ForClass("ascii.graph")).Remove(ForClass("ascii.alnum")).Build()
-	classMap["ascii.blank"] = Make(Pair{'\t', '\t'}, Pair{' ', ' '})
-	classMap["ascii.space"] = Make(Pair{'\t', '\r'}, Pair{' ', ' '})
+	def("ascii", func() Set { return Make(Pair{0x00, 0x7f}) })
+	def("ascii.graph", func() Set { return Make(Pair{0x21, 0x7e}) })
+	def("ascii.print", func() Set { return Make(Pair{0x20, 0x7e}) })
+	def("ascii.cntrl", func() Set { return Make(Pair{0x00, 0x1f}, Pair{0x7f, 0x7f}) })
+	def("ascii.upper", func() Set { return Make(Pair{'A', 'Z'}) })
+	def("ascii.lower", func() Set { return Make(Pair{'a', 'z'}) })
+	def("ascii.alpha", func() Set { return Make(Pair{'A', 'Z'}, Pair{'a', 'z'}) })
+	def("ascii.digit", func() Set { return Make(Pair{'0', '9'}) })
+	def("ascii.bdigit", func() Set { return Make(Pair{'0', '1'}) })
+	def("ascii.odigit", func() Set { return Make(Pair{'0', '7'}) })
+	def("ascii.xdigit", func() Set { return Make(Pair{'0', '9'}, Pair{'A', 'F'}, Pair{'a', 'f'}) })
+	def("ascii.alnum", func() Set { return Make(Pair{'0', '9'}, Pair{'A', 'Z'}, Pair{'a', 'z'}) })
+	def("ascii.word", func() Set { return Make(get("ascii.alnum"), Pair{'_', '_'}) })
+	def("ascii.punct", func() Set { return NewBuilder().Add(get("ascii.graph")).Remove(get("ascii.alnum")).Build() })
+	def("ascii.blank", func() Set { return Make(Pair{'\t', '\t'}, Pair{' ', ' '}) })
+	def("ascii.space", func() Set { return Make(Pair{'\t', '\r'}, Pair{' ', ' '}) })
 
-	classMap["cntrl"] = ForClass("Cc")
-	classMap["upper"] = ForClass("Lu")
-	classMap["title"] = ForClass("Lt")
-	classMap["lower"] = ForClass("Ll")
-	classMap["letter"] = ForClass("L")
-	classMap["alpha"] = ForClass("L")
-	classMap["digit"] = ForClass("Nd")
-	classMap["bdigit"] = ForClass("ascii.bdigit")
-	classMap["odigit"] = ForClass("ascii.odigit")
-	classMap["xdigit"] = ForClass("ascii.xdigit")
-	classMap["number"] = ForClass("N")
-	classMap["alnum"] = Make(ForClass("L"), ForClass("N"))
-	classMap["word"] = Make(ForClass("alnum"), Pair{'_', '_'})
-	classMap["punct"] = ForClass("P")
-	classMap["symbol"] = ForClass("S")
-	classMap["mark"] = ForClass("M")
-	classMap["blank"] = ForClass("ascii.blank")
-	classMap["space"] = Make(ForClass("ascii.space"), ForClass("Z"))
-	classMap["graph"] = Make(ForClass("L"), ForClass("M"), ForClass("N"), ForClass("P"), ForClass("S"))
-	classMap["print"] = Make(ForClass("graph"), ForClass("Zs"))
+	alias("cntrl", "Cc")
+	alias("upper", "Lu")
+	alias("title", "Lt")
+	alias("lower", "Ll")
+	alias("letter", "L")
+	alias("alpha", "L")
+	alias("digit", "Nd")
+	alias("bdigit", "ascii.bdigit")
+	alias("odigit", "ascii.odigit")
+	alias("xdigit", "ascii.xdigit")
+	alias("number", "N")
+	def("alnum", func() Set { return Make(get("L"), get("N")) })
+	def("word", func() Set { return Make(get("alnum"), Pair{'_', '_'}) })
+	alias("punct", "P")
+	alias("symbol", "S")
+	alias("mark", "M")
+	alias("blank", "ascii.blank")
+	def("space", func() Set { return Make(get("ascii.space"), get("Z")) })
+	def("graph", func() Set { return Make(get("L"), get("M"), get("N"), get("P"), get("S")) })
+	def("print", func() Set { return Make(get("graph"), get("Zs")) })
+	return r
 }
@@ -0,0 +1,157 @@
+package runeset
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// Slice returns the Set consisting of the pairs set.At(i) .. set.At(j-1).
+// The result shares the backing array of the original.
+func (set Set) Slice(i uint, j uint) Set {
+	if i == 0 && j == set.Len() {
+		return set
+	}
+	if i >= j {
+		return Empty()
+	}
+	out := set.slice(i, j)
+	return out.withNewInfo()
+}
+
+// ClipView is the part of a Set that lies inside a range of runes, as
+// returned by Set.Clip.  It shares the pairs of the Set, and keeps copies of
+// only the first and last pairs, trimmed to the range.  Call Set to get an
+// ordinary Set.
+type ClipView struct {
+	set   Set
+	i     uint
+	j     uint
+	first Pair
+	last  Pair
+}
+
+// Clip returns the part of the Set inside [lo, hi].  It takes O(log n) time
+// and does not copy the pairs.
+func (set Set) Clip(lo rune, hi rune) ClipView {
+	lo = max(lo, 0)
+	hi = min(hi, unicode.MaxRune)
+	if lo > hi || set.IsEmpty() {
+		return ClipView{}
+	}
+
+	i, _ := set.Search(lo)
+	j, found := set.Search(hi)
+	if found {
+		j++
+	}
+	if i >= j {
+		return ClipView{}
+	}
+
+	first, last := set.At(i), set.At(j-1)
+	first.Lo = max(first.Lo, lo)
+	last.Hi = min(last.Hi, hi)
+	if i+1 == j {
+		first.Hi = last.Hi
+		last = first
+	}
+	return ClipView{set: set, i: i, j: j, first: first, last: last}
+}
+
+// SplitAt partitions the Set into the runes less than ch and the runes
+// greater than or equal to ch.
+func (set Set) SplitAt(ch rune) (below ClipView, atOrAbove ClipView) {
+	if ch > 0 {
+		below = set.Clip(0, ch-1)
+	}
+	atOrAbove = set.Clip(ch, unicode.MaxRune)
+	return below, atOrAbove
+}
+
+const numPlanes = (unicode.MaxRune + 1) >> 16
+
+// Planes partitions the Set into its 17 Unicode planes of 0x10000 runes each.
+func (set Set) Planes() [numPlanes]ClipView {
+	var out [numPlanes]ClipView
+	for p := range out {
+		lo := rune(p) << 16
+		out[p] = set.Clip(lo, lo|0xffff)
+	}
+	return out
+}
+
+func (view ClipView) Len() uint {
+	return view.j - view.i
+}
+
+func (view ClipView) At(index uint) Pair {
+	n := view.Len()
+	switch {
+	case index >= n:
+		panic(fmt.Errorf("index out of range: %d >= %d", index, n))
+	case index == 0:
+		return view.first
+	case index == n-1:
+		return view.last
+	default:
+		return view.set.At(view.i + index)
+	}
+}
+
+func (view ClipView) Search(ch rune) (uint, bool) {
+	return searchSource(view, ch)
+}
+
+func (view ClipView) Contains(ch rune) bool {
+	return view.Len() > 0 && ch >= view.first.Lo && ch <= view.last.Hi && view.set.Contains(ch)
+}
+
+func (view ClipView) IsEmpty() bool {
+	return isEmpty(view)
+}
+
+func (view ClipView) IsFull() bool {
+	return isFull(view)
+}
+
+func (view ClipView) Append(out []byte) []byte {
+	return appendSource(out, view)
+}
+
+func (view ClipView) String() string {
+	return toString(view)
+}
+
+// Clip returns the part of the ClipView inside [lo, hi].
+func (view ClipView) Clip(lo rune, hi rune) ClipView {
+	if view.IsEmpty() {
+		return view
+	}
+	return view.set.Clip(max(lo, view.first.Lo), min(hi, view.last.Hi))
+}
+
+// Set returns the ClipView as an ordinary Set.  If no pair was trimmed, the
+// Set shares the pairs of the original; otherwise they are copied.
+func (view ClipView) Set() Set {
+	n := view.Len()
+	if n <= 0 {
+		return Empty()
+	}
+	if view.first == view.set.At(view.i) && view.last == view.set.At(view.j-1) {
+		return view.set.Slice(view.i, view.j)
+	}
+	list := make([]Pair, n)
+	for i := range list {
+		list[i] = view.At(uint(i))
+	}
+	return makeSet(list)
+}
+
+func (ClipView) canonical() {}
+
+var (
+	_ Source          = ClipView{}
+	_ alwaysCanonical = ClipView{}
+	_ Appender        = ClipView{}
+	_ fmt.Stringer    = ClipView{}
+)
@@ -0,0 +1,57 @@
+package runeset
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSet_Clip(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	for iter := 0; iter < 2000; iter++ {
+		set := Make(randomPairs(rng))
+		lo := rune(rng.Intn(modelDomain+8)) - 4
+		hi := max(lo, 0) + rune(rng.Intn(modelDomain))
+		clip := set.Clip(lo, hi)
+		if actual, expect := clip.String(), set.Builder().IntersectRange(max(lo, 0), hi).String(); actual != expect {
+			t.Fatalf("%v: Clip(%d, %d):\n\texpect: %v\n\tactual: %v", set, lo, hi, expect, actual)
+		}
+		expect := set.Intersect(Make(Pair{max(lo, 0), hi}))
+		if !Equal(clip, expect) || !clip.Set().Equal(expect) || clip.Set().Count() != expect.Count() {
+			t.Fatalf("%v: Clip(%d, %d): not Equal to the intersection", set, lo, hi)
+		}
+		for ch := rune(-1); ch <= modelDomain; ch++ {
+			if clip.Contains(ch) != expect.Contains(ch) {
+				t.Fatalf("%v: Clip(%d, %d): Contains(%d): expect %v", set, lo, hi, ch, expect.Contains(ch))
+			}
+		}
+		if allocs := testing.AllocsPerRun(10, func() { clipSink = set.Clip(lo, hi) }); allocs != 0 {
+			t.Fatalf("%v: Clip(%d, %d): expected no allocations, got %v", set, lo, hi, allocs)
+		}
+
+		if lo+3 <= hi-3 {
+			inner := clip.Clip(lo+3, hi-3)
+			if actual, expect := inner.String(), set.Builder().IntersectRange(max(lo+3, 0), hi-3).String(); actual != expect {
+				t.Fatalf("%v: Clip(%d, %d).Clip:\n\texpect: %v\n\tactual: %v", set, lo, hi, expect, actual)
+			}
+		}
+
+		below, above := set.SplitAt(lo)
+		if !Union(below.Set(), above.Set()).Equal(set) || Overlaps(below, above) {
+			t.Fatalf("%v: SplitAt(%d): %v, %v", set, lo, below, above)
+		}
+	}
+
+	letters := ForClass("L")
+	var planes []Source
+	for p, plane := range letters.Planes() {
+		if !plane.IsEmpty() && (plane.At(0).Lo>>16 != rune(p) || plane.At(plane.Len()-1).Hi>>16 != rune(p)) {
+			t.Errorf("Planes: plane %d is %v", p, plane)
+		}
+		planes = append(planes, plane)
+	}
+	if !Make(planes...).Equal(letters) {
+		t.Errorf("Planes: union is not the original set")
+	}
+}
+
+var clipSink ClipView
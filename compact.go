@@ -0,0 +1,102 @@
+package runeset
+
+// packedPairs stores the pairs of a Set the way unicode.RangeTable does:
+// pairs which lie entirely below U+10000 are held as interleaved Lo/Hi values
+// in r16, and all later pairs in r32.  A pair which straddles U+10000 goes
+// in r32 whole, so that At still yields canonical pairs.
+type packedPairs struct {
+	r16 []uint16
+	r32 []uint32
+}
+
+func (p *packedPairs) len() uint {
+	return uint(len(p.r16)+len(p.r32)) >> 1
+}
+
+func (p *packedPairs) at(index uint) Pair {
+	n16 := uint(len(p.r16)) >> 1
+	if index < n16 {
+		return Pair{rune(p.r16[2*index]), rune(p.r16[2*index+1])}
+	}
+	index -= n16
+	return Pair{rune(p.r32[2*index]), rune(p.r32[2*index+1])}
+}
+
+func (p *packedPairs) slice(i uint, j uint) *packedPairs {
+	n16 := uint(len(p.r16)) >> 1
+	out := &packedPairs{}
+	if i < n16 {
+		out.r16 = p.r16[2*i : 2*min(j, n16)]
+	}
+	if j > n16 {
+		out.r32 = p.r32[2*(max(i, n16)-n16) : 2*(j-n16)]
+	}
+	return out
+}
+
+// packed returns the compact storage of the Set, or nil if its pairs are
+// stored in list.
+func (set Set) packed() *packedPairs {
+	if set.list == nil && set.info != nil {
+		return set.info.packed
+	}
+	return nil
+}
+
+// slice returns the Set with its storage narrowed to pairs i .. j-1, sharing
+// the backing arrays.  The caller must call withNewInfo on the result.
+func (set Set) slice(i uint, j uint) Set {
+	if p := set.packed(); p != nil {
+		return Set{info: &setInfo{packed: p.slice(i, j)}}
+	}
+	set.list = set.list[i:j]
+	return set
+}
+
+// plainList returns the pairs of the Set as a slice, if they are stored as
+// one.
+func (set Set) plainList() ([]Pair, bool) {
+	if set.packed() != nil {
+		return nil, false
+	}
+	return set.list, true
+}
+
+// Compact returns the same Set, with its pairs stored in 16 bits per bound
+// where they fit.  Sets that lie entirely within the BMP take half the
+// memory.  All operations work on compact Sets; they are somewhat slower to
+// index.
+func (set Set) Compact() Set {
+	if set.packed() != nil || set.IsEmpty() {
+		return set
+	}
+	n := set.Len()
+	n16 := uint(0)
+	for n16 < n && set.At(n16).Hi <= 0xffff {
+		n16++
+	}
+	p := &packedPairs{}
+	if n16 > 0 {
+		p.r16 = make([]uint16, 0, 2*n16)
+	}
+	if n > n16 {
+		p.r32 = make([]uint32, 0, 2*(n-n16))
+	}
+	for i := uint(0); i < n; i++ {
+		pair := set.At(i)
+		if i < n16 {
+			p.r16 = append(p.r16, uint16(pair.Lo), uint16(pair.Hi))
+		} else {
+			p.r32 = append(p.r32, uint32(pair.Lo), uint32(pair.Hi))
+		}
+	}
+	return Set{info: &setInfo{latin1: set.info.latin1, packed: p}}
+}
+
+// Footprint returns the number of bytes used to store the pairs of the Set.
+func (set Set) Footprint() uint {
+	if p := set.packed(); p != nil {
+		return uint(len(p.r16))*2 + uint(len(p.r32))*4
+	}
+	return uint(len(set.list)) * 8
+}
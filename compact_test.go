@@ -0,0 +1,54 @@
+package runeset
+
+import (
+	"math/rand"
+	"testing"
+	"unicode"
+)
+
+func TestSet_Compact(t *testing.T) {
+	rng := rand.New(rand.NewSource(10))
+	randomNearBMP := func() Set {
+		var b Builder
+		b.Reset()
+		for i := rng.Intn(8); i > 0; i-- {
+			lo := 0xffc0 + rune(rng.Intn(0x80))
+			b.AddRange(lo, lo+rune(rng.Intn(0x20)))
+		}
+		return b.Build()
+	}
+	for iter := 0; iter < 1000; iter++ {
+		a, b := randomNearBMP(), randomNearBMP()
+		x, y := a.Compact(), b.Compact()
+		if x.String() != a.String() || !x.Equal(a) || !Equal(a, x) || x.Count() != a.Count() {
+			t.Fatalf("Compact(%v): got %v", a, x)
+		}
+		if x.Compare(y) != a.Compare(b) || x.IsSubsetOf(y) != a.IsSubsetOf(b) || x.Overlaps(y) != a.Overlaps(b) {
+			t.Fatalf("relations on Compact(%v), Compact(%v) differ", a, b)
+		}
+		if !x.Union(y).Equal(a.Union(b)) || !x.Difference(y).Equal(a.Difference(b)) || !x.SymmetricDifference(b).Equal(a.SymmetricDifference(b)) {
+			t.Fatalf("algebra on Compact(%v), Compact(%v) differs", a, b)
+		}
+		for ch := rune(0xffb0); ch < 0x10070; ch++ {
+			if x.Contains(ch) != a.Contains(ch) {
+				t.Fatalf("Compact(%v): Contains(%U): expect %v", a, ch, a.Contains(ch))
+			}
+		}
+		lo := 0xffc0 + rune(rng.Intn(0x80))
+		hi := lo + rune(rng.Intn(0x40))
+		if actual, expect := x.Clip(lo, hi).Set(), a.Clip(lo, hi).Set(); !actual.Equal(expect) || actual.Compact().String() != expect.String() {
+			t.Fatalf("Compact(%v).Clip(%U, %U): expect %v, got %v", a, lo, hi, expect, actual)
+		}
+		if n := x.Len(); n > 1 && (!x.Slice(1, n).Equal(a.Slice(1, n)) || x.Slice(1, n).Footprint() != a.Slice(1, n).Compact().Footprint()) {
+			t.Fatalf("Compact(%v).Slice(1, %d): got %v", a, n, x.Slice(1, n))
+		}
+	}
+
+	thai := Make(ForTable(unicode.Thai))
+	if before, after := thai.Footprint(), thai.Compact().Footprint(); after*2 != before {
+		t.Errorf("Thai: Compact footprint %d is not half of %d", after, before)
+	}
+	if class := ForClass("Thai"); !class.Equal(thai) || class.Footprint() != thai.Footprint() {
+		t.Errorf("ForClass(%q): expected %v in %d bytes, got %v in %d", "Thai", thai, thai.Footprint(), class, class.Footprint())
+	}
+}
@@ -0,0 +1,165 @@
+package runeset
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"unicode"
+)
+
+// The text form of a Set is the bracket notation produced by String and
+// accepted by ParseSet.  JSON holds the text form as a string, unless the
+// Set is wrapped in PairsJSON.  Gob uses the layout of SetView.
+
+func (set Set) MarshalText() ([]byte, error) {
+	return set.Append(nil), nil
+}
+
+func (set *Set) UnmarshalText(text []byte) error {
+	parsed, err := ParseSet(string(text))
+	if err != nil {
+		return err
+	}
+	*set = parsed
+	return nil
+}
+
+func (set Set) MarshalJSON() ([]byte, error) {
+	return json.Marshal(set.String())
+}
+
+// UnmarshalJSON accepts either the text form as a JSON string, or the
+// structured form written by PairsJSON.  As with the standard types, JSON
+// null leaves the Set unchanged.
+func (set *Set) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '[' {
+		return (*PairsJSON)(set).UnmarshalJSON(data)
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	return set.UnmarshalText([]byte(str))
+}
+
+// PairsJSON is a Set whose JSON form is an array of [lo, hi] pairs of
+// integer code points, such as [[48,57],[97,122]] for [0-9a-z].  It accepts
+// either form when unmarshaling.
+type PairsJSON Set
+
+func (pj PairsJSON) MarshalJSON() ([]byte, error) {
+	set := Set(pj)
+	n := set.Len()
+	out := make([]byte, 0, 2+16*n)
+	out = append(out, '[')
+	for i := uint(0); i < n; i++ {
+		pair := set.At(i)
+		if i > 0 {
+			out = append(out, ',')
+		}
+		out = fmt.Appendf(out, "[%d,%d]", pair.Lo, pair.Hi)
+	}
+	out = append(out, ']')
+	return out, nil
+}
+
+func (pj *PairsJSON) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		return (*Set)(pj).UnmarshalJSON(data)
+	}
+	var raw [][2]int64
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	b := NewBuilder().CollectErrors()
+	for _, pair := range raw {
+		if err := checkJSONPair(pair); err != nil {
+			return err
+		}
+		b.AddRange(rune(pair[0]), rune(pair[1]))
+	}
+	set, err := b.BuildErr()
+	if err != nil {
+		return err
+	}
+	*pj = PairsJSON(set)
+	return nil
+}
+
+// checkJSONPair reports an error if either bound of pair is not a valid
+// rune.  It checks the JSON numbers themselves, before they are narrowed to
+// runes, so that the error shows the value as it was written.
+func checkJSONPair(pair [2]int64) error {
+	for i, name := range [2]string{"lower", "upper"} {
+		if v := pair[i]; v < 0 || v > unicode.MaxRune {
+			return fmt.Errorf("in pair [%d,%d], %s bound: %d: %w", pair[0], pair[1], name, v, ErrInvalidRune)
+		}
+	}
+	return nil
+}
+
+func (set Set) GobEncode() ([]byte, error) {
+	return AppendSetView(nil, set), nil
+}
+
+func (set *Set) GobDecode(data []byte) error {
+	view, err := NewSetView(data)
+	if err != nil {
+		return err
+	}
+	*set = view.Set()
+	return nil
+}
+
+// Set parses str as ParseSet does, so that *Set implements flag.Value.
+func (set *Set) Set(str string) error {
+	return set.UnmarshalText([]byte(str))
+}
+
+// Scan implements sql.Scanner for columns holding the text form.  NULL
+// scans as the empty Set.
+func (set *Set) Scan(src any) error {
+	switch x := src.(type) {
+	case nil:
+		*set = Empty()
+		return nil
+	case string:
+		return set.UnmarshalText([]byte(x))
+	case []byte:
+		return set.UnmarshalText(x)
+	default:
+		return fmt.Errorf("cannot scan %T into a Set", src)
+	}
+}
+
+// Value implements driver.Valuer, storing the text form.
+func (set Set) Value() (driver.Value, error) {
+	return set.String(), nil
+}
+
+var (
+	_ encoding.TextMarshaler   = Set{}
+	_ encoding.TextUnmarshaler = (*Set)(nil)
+	_ json.Marshaler           = Set{}
+	_ json.Unmarshaler         = (*Set)(nil)
+	_ json.Marshaler           = PairsJSON{}
+	_ json.Unmarshaler         = (*PairsJSON)(nil)
+	_ gob.GobEncoder           = Set{}
+	_ gob.GobDecoder           = (*Set)(nil)
+	_ flag.Value               = (*Set)(nil)
+	_ sql.Scanner              = (*Set)(nil)
+	_ driver.Valuer            = Set{}
+)
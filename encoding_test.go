@@ -0,0 +1,189 @@
+package runeset
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSet_Encoding(t *testing.T) {
+	sets := []Set{
+		Empty(),
+		Full(),
+		MustParseSet(`[\-a-z0-9_]`),
+		ForClass("Greek").Compact(),
+		ForClass("L").Clip(0x100, 0x2ff).Set(),
+	}
+	for _, set := range sets {
+		text, err := set.MarshalText()
+		if err != nil {
+			t.Fatalf("%v: MarshalText: %v", set, err)
+		}
+		var fromText Set
+		if err := fromText.UnmarshalText(text); err != nil || !fromText.Equal(set) {
+			t.Errorf("%v: text round trip: got %v, %v", set, fromText, err)
+		}
+
+		type doc struct {
+			Text  Set
+			Pairs PairsJSON
+		}
+		data, err := json.Marshal(doc{set, PairsJSON(set)})
+		if err != nil {
+			t.Fatalf("%v: json.Marshal: %v", set, err)
+		}
+		var fromJSON doc
+		if err := json.Unmarshal(data, &fromJSON); err != nil || !fromJSON.Text.Equal(set) || !Set(fromJSON.Pairs).Equal(set) {
+			t.Errorf("%v: JSON round trip of %s: got %v, %v, %v", set, data, fromJSON.Text, Set(fromJSON.Pairs), err)
+		}
+
+		var buf bytes.Buffer
+		var fromGob Set
+		if err := gob.NewEncoder(&buf).Encode(set); err != nil {
+			t.Fatalf("%v: gob Encode: %v", set, err)
+		}
+		if err := gob.NewDecoder(&buf).Decode(&fromGob); err != nil || !fromGob.Equal(set) {
+			t.Errorf("%v: gob round trip: got %v, %v", set, fromGob, err)
+		}
+
+		value, _ := set.Value()
+		var fromSQL Set
+		if err := fromSQL.Scan([]byte(value.(string))); err != nil || !fromSQL.Equal(set) {
+			t.Errorf("%v: SQL round trip: got %v, %v", set, fromSQL, err)
+		}
+	}
+
+	if data, _ := json.Marshal(PairsJSON(MustParseSet("[0-9a-z]"))); string(data) != `[[48,57],[97,122]]` {
+		t.Errorf("PairsJSON: got %s", data)
+	}
+	var set Set
+	if err := json.Unmarshal([]byte(`[[97,99],[98,100]]`), &set); err != nil || set.String() != "[a-d]" {
+		t.Errorf("UnmarshalJSON of pairs: got %v, %v", set, err)
+	}
+	if err := json.Unmarshal([]byte(`[[5,1]]`), &set); !errors.Is(err, ErrInvertedPair) {
+		t.Errorf("UnmarshalJSON of inverted pair: got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`[[0,4294967296]]`), &set); !errors.Is(err, ErrInvalidRune) || !strings.Contains(err.Error(), "4294967296") {
+		t.Errorf("UnmarshalJSON of huge rune: got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`[[-1,5]]`), &set); !errors.Is(err, ErrInvalidRune) || !strings.Contains(err.Error(), "lower bound: -1:") {
+		t.Errorf("UnmarshalJSON of negative rune: got %v", err)
+	}
+	set = MustParseSet("[a-d]")
+	pairs := PairsJSON(set)
+	if err := json.Unmarshal([]byte(` null `), &set); err != nil || set.String() != "[a-d]" {
+		t.Errorf("UnmarshalJSON of null: got %v, %v", set, err)
+	}
+	if err := json.Unmarshal([]byte(`null`), &pairs); err != nil || Set(pairs).String() != "[a-d]" {
+		t.Errorf("PairsJSON.UnmarshalJSON of null: got %v, %v", Set(pairs), err)
+	}
+	var nulls struct {
+		Text  Set
+		Pairs PairsJSON
+	}
+	nulls.Text, nulls.Pairs = set, pairs
+	if err := json.Unmarshal([]byte(`{"Text":null,"Pairs":null}`), &nulls); err != nil || !nulls.Text.Equal(set) || !Set(nulls.Pairs).Equal(set) {
+		t.Errorf("UnmarshalJSON of null fields: got %v, %v, %v", nulls.Text, Set(nulls.Pairs), err)
+	}
+	if err := set.Scan(nil); err != nil || !set.IsEmpty() {
+		t.Errorf("Scan(nil): got %v, %v", set, err)
+	}
+	if err := set.Scan(42); err == nil {
+		t.Errorf("Scan(42): expected an error")
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var allow Set
+	fs.Var(&allow, "allow", "allowed runes")
+	if err := fs.Parse([]string{"-allow=[a-z0-9_]"}); err != nil {
+		t.Fatalf("flag: %v", err)
+	}
+	if !allow.Contains('q') || !allow.Contains('_') || allow.Contains('-') {
+		t.Errorf("flag: got %v", allow)
+	}
+	if err := fs.Parse([]string{"-allow=[z-a]"}); err == nil {
+		t.Errorf("flag: expected an error for an inverted range")
+	}
+}
+
+func TestSet_Binary(t *testing.T) {
+	sets := []Set{
+		Empty(),
+		Full(),
+		MustParseSet(`[\0a-z\u{10ffff}]`),
+		ForClass("L"),
+		ForClass("Han").Clip(0x4e00, 0x9fff).Set(),
+	}
+	for _, set := range sets {
+		plain, _ := set.MarshalBinary()
+		checked, _ := set.AppendBinaryChecksum([]byte("prefix"))
+		checked = checked[len("prefix"):]
+		for _, data := range [][]byte{plain, checked} {
+			var out Set
+			if err := out.UnmarshalBinary(data); err != nil || !out.Equal(set) {
+				t.Errorf("%v: binary round trip: got %v, %v", set, out, err)
+			}
+		}
+		if out, err := ParseBase64(set.Base64()); err != nil || !out.Equal(set) {
+			t.Errorf("%v: base64 round trip: got %v, %v", set, out, err)
+		}
+		if set.Len() > 100 && uint(len(plain)) >= set.Footprint()/2 {
+			t.Errorf("%v: binary form takes %d bytes, expected well under %d", set, len(plain), set.Footprint())
+		}
+	}
+
+	letters, _ := ForClass("L").MarshalBinary()
+	var decoded Set
+	if allocs := testing.AllocsPerRun(10, func() { _ = decoded.UnmarshalBinary(letters) }); allocs > 4 {
+		t.Errorf("UnmarshalBinary of L: expected a few allocations, got %v", allocs)
+	}
+
+	if data, _ := MustParseSet("[a-cx]").MarshalBinary(); string(data) != "\x01\x00\x02\x61\x02\x13\x00" {
+		t.Errorf("MarshalBinary: got % x", data)
+	}
+
+	type testRow struct {
+		Name   string
+		Input  string
+		Offset int
+		Err    error
+	}
+	testData := [...]testRow{
+		{"empty", "", 0, nil},
+		{"version", "\x02\x00\x00", 0, nil},
+		{"flags", "\x01\x02\x00", 1, nil},
+		{"truncated count", "\x01\x00", 2, nil},
+		{"count too large", "\x01\x00\x05\x00\x00", 2, nil},
+		{"truncated pair", "\x01\x00\x01\x80\x80", 3, nil},
+		{"overlong varint", "\x01\x00\x01\x80\x00\x00", 3, nil},
+		{"out of range", "\x01\x00\x01\x00\xff\xff\x44", 3, ErrInvalidRune},
+		{"second out of range", "\x01\x00\x02\x00\x00\xff\xff\x43\x00", 5, ErrInvalidRune},
+		{"trailing data", "\x01\x00\x00\x00", 3, nil},
+		{"checksum", "\x01\x01\x00\x00\x00\x00\x00", 3, nil},
+	}
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			var set Set
+			err := set.UnmarshalBinary([]byte(row.Input))
+			var merr MalformedError
+			if !errors.As(err, &merr) {
+				t.Fatalf("expected a MalformedError, got %v", err)
+			}
+			if merr.Offset != row.Offset {
+				t.Errorf("%v: expected offset %d, got %d", err, row.Offset, merr.Offset)
+			}
+			if row.Err != nil && !errors.Is(err, row.Err) {
+				t.Errorf("expected %v, got %v", row.Err, err)
+			}
+		})
+	}
+	if _, err := ParseBase64("not base64!"); !errors.Is(err, ErrMalformed) {
+		t.Errorf("ParseBase64: expected ErrMalformed, got %v", err)
+	}
+}
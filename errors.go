@@ -0,0 +1,95 @@
+package runeset
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidRune    = errors.New("invalid Unicode code point")
+	ErrInvertedPair   = errors.New("lower bound exceeds upper bound")
+	ErrUnknownClass   = errors.New("unknown character class")
+	ErrDuplicateClass = errors.New("duplicate character class")
+	ErrMalformed      = errors.New("malformed serialized set")
+)
+
+type InvalidRuneError struct {
+	Rune rune
+}
+
+func (err InvalidRuneError) Error() string {
+	return fmt.Sprintf("U+%04X is not a valid Unicode code point", uint32(err.Rune))
+}
+
+func (err InvalidRuneError) Is(target error) bool {
+	return target == ErrInvalidRune
+}
+
+type InvertedPairError struct {
+	Pair Pair
+}
+
+func (err InvertedPairError) Error() string {
+	lo := uint32(err.Pair.Lo)
+	hi := uint32(err.Pair.Hi)
+	return fmt.Sprintf("lower bound U+%04X exceeds upper bound U+%04X", lo, hi)
+}
+
+func (err InvertedPairError) Is(target error) bool {
+	return target == ErrInvertedPair
+}
+
+type UnknownClassError struct {
+	Name string
+}
+
+func (err UnknownClassError) Error() string {
+	return fmt.Sprintf("unknown character class %q", err.Name)
+}
+
+func (err UnknownClassError) Is(target error) bool {
+	return target == ErrUnknownClass
+}
+
+type DuplicateClassError struct {
+	Name string
+}
+
+func (err DuplicateClassError) Error() string {
+	return fmt.Sprintf("character class %q is already registered", err.Name)
+}
+
+func (err DuplicateClassError) Is(target error) bool {
+	return target == ErrDuplicateClass
+}
+
+// MalformedError reports serialized set data that cannot be decoded.  Err,
+// if not nil, is the underlying problem, such as an InvalidRuneError.
+type MalformedError struct {
+	Offset int
+	Reason string
+	Err    error
+}
+
+func (err MalformedError) Error() string {
+	if err.Err != nil {
+		return fmt.Sprintf("malformed serialized set at byte %d: %s: %v", err.Offset, err.Reason, err.Err)
+	}
+	return fmt.Sprintf("malformed serialized set at byte %d: %s", err.Offset, err.Reason)
+}
+
+func (err MalformedError) Unwrap() error {
+	return err.Err
+}
+
+func (err MalformedError) Is(target error) bool {
+	return target == ErrMalformed
+}
+
+var (
+	_ error = InvalidRuneError{}
+	_ error = InvertedPairError{}
+	_ error = UnknownClassError{}
+	_ error = DuplicateClassError{}
+	_ error = MalformedError{}
+)
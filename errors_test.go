@@ -0,0 +1,69 @@
+package runeset
+
+import (
+	"errors"
+	"testing"
+	"unicode"
+)
+
+func TestErrors(t *testing.T) {
+	if _, err := NewPair('a', 'z'); err != nil {
+		t.Errorf("NewPair('a', 'z'): unexpected error: %v", err)
+	}
+
+	_, err := NewPair('z', 'a')
+	var inverted InvertedPairError
+	if !errors.Is(err, ErrInvertedPair) || !errors.As(err, &inverted) {
+		t.Errorf("NewPair('z', 'a'): expected ErrInvertedPair, got %v", err)
+	} else if inverted.Pair != (Pair{'z', 'a'}) {
+		t.Errorf("NewPair('z', 'a'): wrong pair in error: %#v", inverted.Pair)
+	}
+
+	_, err = NewPair('a', unicode.MaxRune+1)
+	var invalid InvalidRuneError
+	if !errors.Is(err, ErrInvalidRune) || !errors.As(err, &invalid) {
+		t.Errorf("NewPair('a', MaxRune+1): expected ErrInvalidRune, got %v", err)
+	} else if invalid.Rune != unicode.MaxRune+1 {
+		t.Errorf("NewPair('a', MaxRune+1): wrong rune in error: %#x", invalid.Rune)
+	}
+	if actual, expect := err.Error(), "in pair U+0061..U+110000, upper bound: U+110000 is not a valid Unicode code point"; actual != expect {
+		t.Errorf("NewPair('a', MaxRune+1): wrong message:\n\texpect: %q\n\tactual: %q", expect, actual)
+	}
+
+	if err := Rune(-1).Validate(); !errors.Is(err, ErrInvalidRune) {
+		t.Errorf("Rune(-1).Validate(): expected ErrInvalidRune, got %v", err)
+	}
+
+	if _, found := LookupClass("no.such.class"); found {
+		t.Errorf("LookupClass: found a class that does not exist")
+	}
+
+	_, err = ParseExpression(`[\p{no.such.class}]`)
+	var unknown UnknownClassError
+	if !errors.Is(err, ErrUnknownClass) || !errors.As(err, &unknown) {
+		t.Errorf("ParseExpression: expected ErrUnknownClass, got %v", err)
+	} else if unknown.Name != "no.such.class" {
+		t.Errorf("ParseExpression: wrong name in error: %q", unknown.Name)
+	}
+}
+
+func TestBuilder_CollectErrors(t *testing.T) {
+	b := NewBuilder().CollectErrors()
+	b.AddRange('a', 'c').AddRange('z', 'x').AddRune(-1).AddRange('g', 'i')
+	if actual, expect := b.String(), `[a-cg-i]`; actual != expect {
+		t.Errorf("wrong set:\n\texpect: %q\n\tactual: %q", expect, actual)
+	}
+
+	set, err := b.BuildErr()
+	if !errors.Is(err, ErrInvertedPair) {
+		t.Errorf("BuildErr: expected first error to be ErrInvertedPair, got %v", err)
+	}
+	if !set.IsEmpty() {
+		t.Errorf("BuildErr: expected empty set on error, got %v", set)
+	}
+
+	b.Reset().AddRange('a', 'c')
+	if set, err := b.BuildErr(); err != nil || set.String() != `[a-c]` {
+		t.Errorf("BuildErr after Reset: got %v, %v", set, err)
+	}
+}
@@ -0,0 +1,274 @@
+package runeset
+
+import (
+	"strings"
+)
+
+// ParseExpression compiles a UTS #18 style set expression.
+//
+// A bracketed set contains a sequence of operands, optionally preceded by
+// "^" to negate the result.  An operand is a rune, a rune range "a-z", a
+// named class "\p{Name}" or "[:name:]", or another bracketed set.
+// Adjacent operands are unioned; the binary operators "||" (union), "&&"
+// (intersection), "--" (difference) and "~~" (symmetric difference) then
+// combine those unions from left to right.
+//
+// The top level of the expression is treated like the inside of a
+// bracketed set, so "\p{L}--[a-z]" and "[\p{L}--[a-z]]" are equivalent.
+func ParseExpression(str string) (Set, error) {
+	return DefaultRegistry().ParseExpression(str)
+}
+
+func MustParseExpression(str string) Set {
+	set, err := ParseExpression(str)
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
+
+type setOp byte
+
+const (
+	opNone setOp = iota
+	opUnion
+	opIntersect
+	opDifference
+	opSymmetricDifference
+	opComplement
+)
+
+type exprParser struct {
+	parser
+	classes *Registry
+}
+
+func (p *exprParser) parseExpression() (Set, error) {
+	if p.atEnd() {
+		return Empty(), p.fail(0, "empty expression")
+	}
+	b, err := p.parseBody(false)
+	if err != nil {
+		return Empty(), err
+	}
+	return b.Build(), nil
+}
+
+func (p *exprParser) parseOperator() setOp {
+	var op setOp
+	switch {
+	case p.hasPrefix("||"):
+		op = opUnion
+	case p.hasPrefix("&&"):
+		op = opIntersect
+	case p.hasPrefix("--"):
+		op = opDifference
+	case p.hasPrefix("~~"):
+		op = opSymmetricDifference
+	default:
+		return opNone
+	}
+	p.pos += 2
+	return op
+}
+
+func (p *exprParser) parseBody(nested bool) (*Builder, error) {
+	start := p.pos
+	acc, n, err := p.parseUnion(nested)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		opStart := p.pos
+		op := p.parseOperator()
+		if op == opNone {
+			break
+		}
+		if n <= 0 {
+			return nil, p.fail(start, "missing left operand")
+		}
+
+		rhs, m, err := p.parseUnion(nested)
+		if err != nil {
+			return nil, err
+		}
+		if m <= 0 {
+			return nil, p.fail(opStart, "missing right operand")
+		}
+
+		switch op {
+		case opUnion:
+			acc.Add(rhs)
+		case opIntersect:
+			acc.Intersect(rhs)
+		case opDifference:
+			acc.Remove(rhs)
+		case opSymmetricDifference:
+			both := acc.Clone().Intersect(rhs)
+			acc.Add(rhs).Remove(both)
+		}
+	}
+	return acc, nil
+}
+
+// parseUnion parses adjacent operands up to the next operator or the end of
+// the enclosing set, and returns their union and the operand count.
+func (p *exprParser) parseUnion(nested bool) (*Builder, int, error) {
+	b := NewBuilder()
+	n := 0
+	for {
+		ch, size := p.peek()
+		switch {
+		case size == 0 && nested:
+			return nil, 0, p.fail(p.pos, "unterminated set: missing ']'")
+		case size == 0:
+			return b, n, nil
+		case ch == ']' && nested:
+			return b, n, nil
+		case ch == ']':
+			return nil, 0, p.fail(p.pos, "unexpected ']'")
+		case p.hasPrefix("||") || p.hasPrefix("&&") || p.hasPrefix("--") || p.hasPrefix("~~"):
+			return b, n, nil
+		}
+
+		src, err := p.parseOperand()
+		if err != nil {
+			return nil, 0, err
+		}
+		b.Add(src)
+		n++
+	}
+}
+
+func (p *exprParser) parseOperand() (Source, error) {
+	switch {
+	case p.hasPrefix("[:"):
+		return p.parsePosixClass()
+	case p.hasPrefix("["):
+		return p.parseBracket()
+	case p.hasPrefix("\\"):
+		if src, ok, err := p.parseClassEscape(); ok || err != nil {
+			return src, err
+		}
+	}
+
+	start := p.pos
+	lo, err := p.parseRune()
+	if err != nil {
+		return nil, err
+	}
+	hi := lo
+	if p.hasPrefix("-") && !p.hasPrefix("--") {
+		p.pos++
+		hi, err = p.parseRune()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if lo > hi {
+		return nil, p.failWith(start, InvertedPairError{Pair{lo, hi}})
+	}
+	return Pair{lo, hi}, nil
+}
+
+func (p *exprParser) parseBracket() (Source, error) {
+	p.pos++
+	negate := false
+	if p.hasPrefix("^") {
+		p.pos++
+		negate = true
+	}
+	b, err := p.parseBody(true)
+	if err != nil {
+		return nil, err
+	}
+	p.pos++
+	if negate {
+		b.Negate()
+	}
+	return b, nil
+}
+
+func (p *exprParser) parsePosixClass() (Source, error) {
+	start := p.pos
+	end := strings.Index(p.input[p.pos+2:], ":]")
+	if end < 0 {
+		return nil, p.fail(start, "unterminated class: missing ':]'")
+	}
+	end += 2
+	name := p.input[p.pos+2 : p.pos+end]
+	if name == "" || name == "^" {
+		return nil, p.fail(start, "empty class name")
+	}
+	p.pos += end + 2
+	return p.resolveClass(start, name)
+}
+
+// parseClassEscape parses "\p{Name}", "\pL", their negated "\P" forms, and
+// the shorthand classes "\d", "\s" and "\w" and their negations.  It returns
+// false if the escape at the current position is not a class escape.
+func (p *exprParser) parseClassEscape() (Source, bool, error) {
+	start := p.pos
+	if len(p.input)-p.pos < 2 {
+		return nil, false, nil
+	}
+
+	var name string
+	negate := false
+	switch ch := p.input[p.pos+1]; ch {
+	case 'd', 'D':
+		name, negate = "digit", ch == 'D'
+		p.pos += 2
+	case 's', 'S':
+		name, negate = "space", ch == 'S'
+		p.pos += 2
+	case 'w', 'W':
+		name, negate = "word", ch == 'W'
+		p.pos += 2
+	case 'p', 'P':
+		negate = ch == 'P'
+		p.pos += 2
+		if p.hasPrefix("{") {
+			end := strings.IndexByte(p.input[p.pos:], '}')
+			if end < 0 {
+				return nil, true, p.fail(start, "unterminated class: missing '}'")
+			}
+			name = p.input[p.pos+1 : p.pos+end]
+			p.pos += end + 1
+		} else {
+			ch, size := p.peek()
+			if size == 0 || ch >= 0x80 {
+				return nil, true, p.fail(start, "expected '{' or a one-letter class name after \\%c", p.input[start+1])
+			}
+			name = string(ch)
+			p.pos += size
+		}
+	default:
+		return nil, false, nil
+	}
+
+	src, err := p.resolveClass(start, name)
+	if err != nil {
+		return nil, true, err
+	}
+	if negate {
+		src = NewBuilder().Add(src).Negate()
+	}
+	return src, true, nil
+}
+
+func (p *exprParser) resolveClass(start int, name string) (Source, error) {
+	negate := false
+	if strings.HasPrefix(name, "^") {
+		name = name[1:]
+		negate = true
+	}
+	set, found := p.classes.LookupClass(name)
+	if !found {
+		return nil, p.failWith(start, UnknownClassError{name})
+	}
+	if negate {
+		return set.Builder().Negate(), nil
+	}
+	return set, nil
+}
@@ -0,0 +1,88 @@
+package runeset
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseExpression(t *testing.T) {
+	type testRow struct {
+		Name   string
+		Input  string
+		Expect string
+		Offset int
+	}
+
+	testData := [...]testRow{
+		{Name: "Rune", Input: `a`, Expect: `[a]`},
+		{Name: "Bracket", Input: `[a-cx]`, Expect: `[a-cx]`},
+		{Name: "EmptyBracket", Input: `[]`, Expect: `!.`},
+		{Name: "NegatedEmpty", Input: `[^]`, Expect: `.`},
+		{Name: "Negate", Input: `[^\0-\x40\x5b-\z]`, Expect: `[A-Z]`},
+		{Name: "Posix", Input: `[[:ascii.alpha:][:ascii.digit:]_]`, Expect: `[0-9A-Z\x5fa-z]`},
+		{Name: "PosixNegated", Input: `[[:ascii:]&&[:^ascii.graph:]]`, Expect: `[\0-\x20\x7f]`},
+		{Name: "Property", Input: `[\p{ascii.xdigit}--[a-f]]`, Expect: `[0-9A-F]`},
+		{Name: "NegatedProperty", Input: `[\P{ascii.lower}&&[a-z0-9]]`, Expect: `[0-9]`},
+		{Name: "Intersect", Input: `[a-m&&h-z]`, Expect: `[h-m]`},
+		{Name: "Difference", Input: `[a-z--[aeiou]]`, Expect: `[b-df-hj-np-tv-z]`},
+		{Name: "SymmetricDifference", Input: `[a-m~~h-z]`, Expect: `[a-gn-z]`},
+		{Name: "ExplicitUnion", Input: `[a-c||x-z]`, Expect: `[a-cx-z]`},
+		{Name: "LeftToRight", Input: `[a-z--a-m&&k-p]`, Expect: `[n-p]`},
+		{Name: "UnionBindsTighter", Input: `[a-cx-z&&b-y]`, Expect: `[b-cx-y]`},
+		{Name: "Nested", Input: `[[:ascii.alpha:]&&[^[:ascii.upper:]]--[a-m]]`, Expect: `[n-z]`},
+		{Name: "TopLevelOperator", Input: `[a-z]--[aeiou]`, Expect: `[b-df-hj-np-tv-z]`},
+		{Name: "Shorthand", Input: `[\d&&[:ascii:]]`, Expect: `[0-9]`},
+		{Name: "Escapes", Input: `[\-\&\[\]]`, Expect: `[\x26\x2d\x5b\x5d]`},
+
+		{Name: "Err-Empty", Input: ``, Offset: 0},
+		{Name: "Err-Unterminated", Input: `[a-z`, Offset: 4},
+		{Name: "Err-StrayBracket", Input: `a]`, Offset: 1},
+		{Name: "Err-UnknownClass", Input: `[a\p{NoSuchClass}]`, Offset: 2},
+		{Name: "Err-UnknownPosix", Input: `[[:nope:]]`, Offset: 1},
+		{Name: "Err-PosixOverlap", Input: `[:]`, Offset: 0},
+		{Name: "Err-PosixOverlapNested", Input: `[[:]]`, Offset: 1},
+		{Name: "Err-PosixEmpty", Input: `[[::]]`, Offset: 1},
+		{Name: "Err-MissingRight", Input: `[a-z&&]`, Offset: 4},
+		{Name: "Err-MissingLeft", Input: `[&&a-z]`, Offset: 1},
+		{Name: "Err-Inverted", Input: `[z-a]`, Offset: 1},
+		{Name: "Err-BadEscape", Input: `[\q]`, Offset: 1},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			set, err := ParseExpression(row.Input)
+			if row.Expect == "" {
+				var perr *ParseError
+				if !errors.As(err, &perr) {
+					t.Fatalf("expected *ParseError, got %v (set %v)", err, set)
+				}
+				if perr.Offset != row.Offset {
+					t.Errorf("wrong offset: expect %d, actual %d (%v)", row.Offset, perr.Offset, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := set.String(); actual != row.Expect {
+				t.Errorf("wrong set:\n\texpect: %q\n\tactual: %q", row.Expect, actual)
+			}
+		})
+	}
+}
+
+func TestParseExpression_Unicode(t *testing.T) {
+	expect := NewBuilder().
+		Add(ForClass("L")).
+		Remove(ForClass("Lu")).
+		RemoveRange('a', 'z').
+		Build()
+
+	actual, err := ParseExpression(`[\p{L}&&[^\p{Lu}]--[a-z]]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual.String() != expect.String() {
+		t.Errorf("wrong set:\n\texpect: %v\n\tactual: %v", expect, actual)
+	}
+}
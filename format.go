@@ -0,0 +1,244 @@
+package runeset
+
+import (
+	"fmt"
+)
+
+// Style selects how AppendFormat writes a Source.
+type Style byte
+
+const (
+	// StyleDefault is the bracket notation of String, with letters and
+	// digits written as themselves and everything else escaped.
+	StyleDefault Style = iota
+
+	// StyleEscaped is the bracket notation with every rune escaped except
+	// ASCII letters and digits, so that the output is plain ASCII.
+	StyleEscaped
+
+	// StyleUCD lists the pairs as the Unicode Character Database does,
+	// e.g. "U+0030..U+0039 U+005F", separated by spaces.
+	StyleUCD
+
+	// StyleGo is a Go expression that evaluates to an equal value, as
+	// written by the %#v verb.
+	StyleGo
+)
+
+func (style Style) String() string {
+	switch style {
+	case StyleDefault:
+		return "StyleDefault"
+	case StyleEscaped:
+		return "StyleEscaped"
+	case StyleUCD:
+		return "StyleUCD"
+	case StyleGo:
+		return "StyleGo"
+	default:
+		return fmt.Sprintf("Style(%d)", byte(style))
+	}
+}
+
+// appendFormat appends src in the given Style.  StyleGo is handled by the
+// caller, which knows the concrete type of src.
+func appendFormat[S Source](out []byte, src S, style Style) []byte {
+	switch style {
+	case StyleDefault:
+		return appendSource(out, src)
+	case StyleEscaped:
+		return appendBracketed(out, src, appendASCIIRune)
+	case StyleUCD:
+		n := src.Len()
+		for i := uint(0); i < n; i++ {
+			p := src.At(i)
+			if i > 0 {
+				out = append(out, ' ')
+			}
+			out = fmt.Appendf(out, "U+%04X", uint32(p.Lo))
+			if p.Lo != p.Hi {
+				out = fmt.Appendf(out, "..U+%04X", uint32(p.Hi))
+			}
+		}
+		return out
+	default:
+		panic(fmt.Errorf("unknown Style %v", style))
+	}
+}
+
+// formatSource implements fmt.Formatter for the Source types.  %v writes
+// StyleDefault, %+v adds the number of pairs and runes, and %#v writes
+// StyleGo; width, precision and the - flag apply as they do to %s.  %s, %q,
+// %x and %X format the String of src, as fmt does for any fmt.Stringer.
+// Other verbs format raw, the value without its methods, if it is not nil.
+func formatSource[S Source](f fmt.State, verb rune, src S, appendGo func([]byte) []byte, raw any) {
+	var scratch [64]byte
+	out := scratch[:0]
+	switch verb {
+	case 'v':
+		if f.Flag('#') {
+			out = appendGo(out)
+		} else {
+			out = appendSource(out, src)
+			if f.Flag('+') {
+				out = appendCounts(out, src)
+			}
+		}
+		fmt.Fprintf(f, fmt.FormatString(f, 's'), string(out))
+	case 's', 'q', 'x', 'X':
+		fmt.Fprintf(f, fmt.FormatString(f, verb), string(appendSource(out, src)))
+	default:
+		if raw != nil {
+			fmt.Fprintf(f, fmt.FormatString(f, verb), raw)
+			return
+		}
+		out = fmt.Appendf(out, "%%!%c(%T=%s)", verb, src, appendSource(nil, src))
+		_, _ = f.Write(out)
+	}
+}
+
+func appendCounts[S Source](out []byte, src S) []byte {
+	// count only the valid pairs, rather than panic while formatting
+	var b Builder
+	canon := b.CollectErrors().normalize(src)
+	n := canon.Len()
+	var count uint
+	for i := uint(0); i < n; i++ {
+		p := canon.At(i)
+		count += uint(p.Hi-p.Lo) + 1
+	}
+	return fmt.Appendf(out, " (%d %s, %d %s)", n, plural(n, "pair", "pairs"), count, plural(count, "rune", "runes"))
+}
+
+func plural(n uint, one string, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+func appendGoPair(out []byte, pair Pair) []byte {
+	return fmt.Appendf(out, "{Lo: %#x, Hi: %#x}", pair.Lo, pair.Hi)
+}
+
+func appendGoPairList(out []byte, list []Pair) []byte {
+	out = append(out, "runeset.PairList{"...)
+	for i, pair := range list {
+		if i > 0 {
+			out = append(out, ", "...)
+		}
+		out = appendGoPair(out, pair)
+	}
+	return append(out, '}')
+}
+
+func (set Set) appendGo(out []byte) []byte {
+	switch {
+	case set.IsEmpty():
+		return append(out, "runeset.Empty()"...)
+	case set.IsFull():
+		return append(out, "runeset.Full()"...)
+	}
+	n := set.Len()
+	list := make([]Pair, n)
+	for i := range list {
+		list[i] = set.At(uint(i))
+	}
+	out = append(out, "runeset.Make("...)
+	out = appendGoPairList(out, list)
+	return append(out, ')')
+}
+
+func (pair Pair) appendGo(out []byte) []byte {
+	out = append(out, "runeset.Pair"...)
+	return appendGoPair(out, pair)
+}
+
+func (r Rune) appendGo(out []byte) []byte {
+	return fmt.Appendf(out, "runeset.Rune(%#x)", rune(r))
+}
+
+func (list PairList) appendGo(out []byte) []byte {
+	if list == nil {
+		return append(out, "runeset.PairList(nil)"...)
+	}
+	return appendGoPairList(out, list)
+}
+
+func (list RuneList) appendGo(out []byte) []byte {
+	if list == nil {
+		return append(out, "runeset.RuneList(nil)"...)
+	}
+	out = append(out, "runeset.RuneList{"...)
+	for i, ch := range list {
+		if i > 0 {
+			out = append(out, ", "...)
+		}
+		out = fmt.Appendf(out, "%#x", ch)
+	}
+	return append(out, '}')
+}
+
+func (set Set) Format(f fmt.State, verb rune) {
+	formatSource(f, verb, set, set.appendGo, nil)
+}
+
+func (set Set) AppendFormat(out []byte, style Style) []byte {
+	if style == StyleGo {
+		return set.appendGo(out)
+	}
+	return appendFormat(out, set, style)
+}
+
+func (pair Pair) Format(f fmt.State, verb rune) {
+	formatSource(f, verb, pair, pair.appendGo, struct{ Lo, Hi rune }(pair))
+}
+
+func (pair Pair) AppendFormat(out []byte, style Style) []byte {
+	if style == StyleGo {
+		return pair.appendGo(out)
+	}
+	return appendFormat(out, pair, style)
+}
+
+func (r Rune) Format(f fmt.State, verb rune) {
+	formatSource(f, verb, r, r.appendGo, rune(r))
+}
+
+func (r Rune) AppendFormat(out []byte, style Style) []byte {
+	if style == StyleGo {
+		return r.appendGo(out)
+	}
+	return appendFormat(out, r, style)
+}
+
+func (list PairList) Format(f fmt.State, verb rune) {
+	formatSource(f, verb, list, list.appendGo, []Pair(list))
+}
+
+func (list PairList) AppendFormat(out []byte, style Style) []byte {
+	if style == StyleGo {
+		return list.appendGo(out)
+	}
+	return appendFormat(out, list, style)
+}
+
+func (list RuneList) Format(f fmt.State, verb rune) {
+	formatSource(f, verb, list, list.appendGo, []rune(list))
+}
+
+func (list RuneList) AppendFormat(out []byte, style Style) []byte {
+	if style == StyleGo {
+		return list.appendGo(out)
+	}
+	return appendFormat(out, list, style)
+}
+
+var (
+	_ fmt.Formatter = Set{}
+	_ fmt.Formatter = Pair{}
+	_ fmt.Formatter = Rune(0)
+	_ fmt.Formatter = PairList(nil)
+	_ fmt.Formatter = RuneList(nil)
+	_ fmt.Stringer  = Style(0)
+)
@@ -0,0 +1,86 @@
+package runeset
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	type testRow struct {
+		Name   string
+		Format string
+		Input  any
+		Expect string
+	}
+
+	word := MustParseSet(`[0-9A-Z_a-z]`)
+	mixed := MustParseSet(`[a\u0301\u4e00-\u4e02]`)
+	testData := [...]testRow{
+		{"Set/v", "%v", word, `[0-9A-Z\x5fa-z]`},
+		{"Set/s", "%s", word, `[0-9A-Z\x5fa-z]`},
+		{"Set/+v", "%+v", word, `[0-9A-Z\x5fa-z] (4 pairs, 63 runes)`},
+		{"Set/+v/single", "%+v", MustParseSet("[a]"), `[a] (1 pair, 1 rune)`},
+		{"Set/#v", "%#v", word, `runeset.Make(runeset.PairList{{Lo: 0x30, Hi: 0x39}, {Lo: 0x41, Hi: 0x5a}, {Lo: 0x5f, Hi: 0x5f}, {Lo: 0x61, Hi: 0x7a}})`},
+		{"Set/#v/empty", "%#v", Empty(), `runeset.Empty()`},
+		{"Set/#v/full", "%#v", Full(), `runeset.Full()`},
+		{"Set/q", "%q", mixed, `"[a\\u0301一-丂]"`},
+		{"Set/d", "%d", word, `%!d(runeset.Set=[0-9A-Z\x5fa-z])`},
+		{"Set/x", "%x", MustParseSet("[a-c]"), `5b612d635d`},
+		{"Set/X", "% X", MustParseSet("[a-c]"), `5B 61 2D 63 5D`},
+		{"Set/+q", "%+q", mixed, `"[a\\u0301\u4e00-\u4e02]"`},
+		{"Set/width", "%-10v|%8s|", []any{MustParseSet("[a-c]"), MustParseSet("[a-c]")}, `[a-c]     |   [a-c]|`},
+		{"Set/+v/width", "%25v|%-+25v|", []any{word, MustParseSet("[a]")}, `          [0-9A-Z\x5fa-z]|[a] (1 pair, 1 rune)     |`},
+		{"Set/precision", "%.3v|%.2q", []any{word, word}, `[0-|"[0"`},
+		{"Set/#v/width", "%#20v", []any{Empty()}, `     runeset.Empty()`},
+		{"Pair/v", "%v", Pair{'a', 'z'}, `[a-z]`},
+		{"Pair/#v", "%#v", Pair{'a', 'z'}, `runeset.Pair{Lo: 0x61, Hi: 0x7a}`},
+		{"Pair/d", "%d", Pair{'a', 'z'}, `{97 122}`},
+		{"Rune/v", "%v", Rune('x'), `[x]`},
+		{"Rune/+v", "%+v", Rune('x'), `[x] (1 pair, 1 rune)`},
+		{"Rune/#v", "%#v", Rune('x'), `runeset.Rune(0x78)`},
+		{"Rune/d", "%d", Rune('x'), `120`},
+		{"Rune/x", "%x", Rune('x'), `5b785d`},
+		{"Rune/width", "%5v|%-5d|", []any{Rune('x'), Rune('x')}, `  [x]|120  |`},
+		{"PairList/v", "%v", PairList{{'x', 'z'}, {'a', 'c'}}, `[x-za-c]`},
+		{"PairList/width", "%-10s|", []any{PairList{{'x', 'z'}}}, `[x-z]     |`},
+		{"PairList/+v", "%+v", PairList{{'x', 'z'}, {'a', 'c'}, {'b', 'd'}}, `[x-za-cb-d] (2 pairs, 7 runes)`},
+		{"PairList/#v", "%#v", PairList{{'x', 'z'}}, `runeset.PairList{{Lo: 0x78, Hi: 0x7a}}`},
+		{"PairList/#v/nil", "%#v", PairList(nil), `runeset.PairList(nil)`},
+		{"RuneList/v", "%v", RuneList{'b', 'a'}, `[ba]`},
+		{"RuneList/#v", "%#v", RuneList{'b', 'a'}, `runeset.RuneList{0x62, 0x61}`},
+		{"RuneList/d", "%d", RuneList{'b', 'a'}, `[98 97]`},
+	}
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			args, ok := row.Input.([]any)
+			if !ok {
+				args = []any{row.Input}
+			}
+			if actual := fmt.Sprintf(row.Format, args...); actual != row.Expect {
+				t.Errorf("expect %s, got %s", row.Expect, actual)
+			}
+		})
+	}
+
+	if actual, expect := string(mixed.AppendFormat(nil, StyleEscaped)), `[a\u0301\u4e00-\u4e02]`; actual != expect {
+		t.Errorf("StyleEscaped: expect %s, got %s", expect, actual)
+	}
+	if actual, expect := string(word.AppendFormat([]byte("x="), StyleUCD)), `x=U+0030..U+0039 U+0041..U+005A U+005F U+0061..U+007A`; actual != expect {
+		t.Errorf("StyleUCD: expect %s, got %s", expect, actual)
+	}
+	if actual, expect := string(Rune(0x10ffff).AppendFormat(nil, StyleUCD)), `U+10FFFF`; actual != expect {
+		t.Errorf("StyleUCD: expect %s, got %s", expect, actual)
+	}
+	for _, name := range []string{"L", "Zs", "Han", "ascii.punct"} {
+		set := ForClass(name)
+		escaped := set.AppendFormat(nil, StyleEscaped)
+		for _, b := range escaped {
+			if b >= 0x80 {
+				t.Fatalf("%s: StyleEscaped output is not ASCII", name)
+			}
+		}
+		if parsed, err := ParseSet(string(escaped)); err != nil || !parsed.Equal(set) {
+			t.Errorf("%s: StyleEscaped does not round trip: %v", name, err)
+		}
+	}
+}
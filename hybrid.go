@@ -0,0 +1,455 @@
+package runeset
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+	"sync"
+	"unicode"
+)
+
+// Hybrid stores a set of runes as a sorted list of 4096-rune chunks, in the
+// manner of a Roaring bitmap.  Each non-empty chunk is held in whichever of
+// three containers suits it: a sorted array of runes, a bitmap, or a list of
+// runs.  Sparse chunks start out as arrays and dense chunks as bitmaps;
+// Optimize converts each chunk to its smallest container.
+//
+// Contains and the set algebra work on the chunks directly, and each chunk of
+// a result is stored in the smallest container for it.  Len and At need the
+// runs of every chunk in order, so they go through the Set method, which
+// gathers them once.
+type Hybrid struct {
+	chunks []hybridChunk
+	once   sync.Once
+	set    Set
+}
+
+const (
+	hybridChunkBits   = 12
+	hybridChunkLen    = 1 << hybridChunkBits
+	hybridChunkMask   = hybridChunkLen - 1
+	hybridNumChunks   = (unicode.MaxRune + 1) >> hybridChunkBits
+	hybridBitmapWords = hybridChunkLen / 64
+
+	// hybridArrayMax is the cardinality at which an array takes as much
+	// space as a bitmap.
+	hybridArrayMax = hybridBitmapWords * 8 / 2
+)
+
+type hybridBitmap [hybridBitmapWords]uint64
+
+type containerKind byte
+
+const (
+	containerArray containerKind = iota
+	containerBitmap
+	containerRuns
+)
+
+type hybridChunk struct {
+	key    uint16
+	kind   containerKind
+	card   uint16
+	array  []uint16
+	bitmap *hybridBitmap
+	runs   []hybridRun
+}
+
+type hybridRun struct {
+	lo uint16
+	hi uint16
+}
+
+func NewHybrid(sources ...Source) *Hybrid {
+	set := Make(sources...)
+	h := &Hybrid{}
+	var bm hybridBitmap
+	key := -1
+	flush := func() {
+		if key >= 0 {
+			h.appendChunk(uint16(key), &bm)
+			bm = hybridBitmap{}
+		}
+	}
+	set.EachPair(func(pair Pair) bool {
+		for lo := pair.Lo; lo <= pair.Hi; {
+			k := int(lo >> hybridChunkBits)
+			if k != key {
+				flush()
+				key = k
+			}
+			hi := min(pair.Hi, lo|hybridChunkMask)
+			setBitRange(bm[:], uint32(lo&hybridChunkMask), uint32(hi&hybridChunkMask))
+			lo = hi + 1
+		}
+		return true
+	})
+	flush()
+	return h
+}
+
+// appendChunk appends the contents of bm as the chunk for key, unless bm is
+// empty.  Keys must be appended in increasing order.
+func (h *Hybrid) appendChunk(key uint16, bm *hybridBitmap) {
+	card := 0
+	for _, word := range bm {
+		card += bits.OnesCount64(word)
+	}
+	switch {
+	case card == 0:
+		return
+	case card <= hybridArrayMax:
+		array := make([]uint16, 0, card)
+		eachBit(bm, func(off uint16) {
+			array = append(array, off)
+		})
+		h.chunks = append(h.chunks, hybridChunk{key: key, kind: containerArray, card: uint16(card), array: array})
+	default:
+		copied := *bm
+		h.chunks = append(h.chunks, hybridChunk{key: key, kind: containerBitmap, card: uint16(card), bitmap: &copied})
+	}
+}
+
+// appendSmallest appends the contents of bm as the chunk for key, in
+// whichever container takes the least space, unless bm is empty.
+func (h *Hybrid) appendSmallest(key uint16, bm *hybridBitmap) {
+	card := 0
+	for _, word := range bm {
+		card += bits.OnesCount64(word)
+	}
+	if card == 0 {
+		return
+	}
+	c := hybridChunk{key: key, kind: containerBitmap, card: uint16(card), bitmap: bm}
+	c = c.optimized()
+	if c.kind == containerBitmap {
+		copied := *bm
+		c.bitmap = &copied
+	}
+	h.chunks = append(h.chunks, c)
+}
+
+func eachBit(bm *hybridBitmap, fn func(uint16)) {
+	for w, word := range bm {
+		for word != 0 {
+			bit := bits.TrailingZeros64(word)
+			fn(uint16(w<<6 | bit))
+			word &= word - 1
+		}
+	}
+}
+
+func (c *hybridChunk) contains(off uint16) bool {
+	switch c.kind {
+	case containerArray:
+		_, found := slices.BinarySearch(c.array, off)
+		return found
+	case containerBitmap:
+		return (c.bitmap[off>>6] & (1 << (off & 63))) != 0
+	case containerRuns:
+		i, _ := slices.BinarySearchFunc(c.runs, off, func(run hybridRun, off uint16) int {
+			return int(run.hi) - int(off)
+		})
+		return i < len(c.runs) && c.runs[i].lo <= off
+	default:
+		panic(fmt.Errorf("BUG: unknown container kind %d", c.kind))
+	}
+}
+
+func (c *hybridChunk) toBitmap(bm *hybridBitmap) {
+	switch c.kind {
+	case containerArray:
+		for _, off := range c.array {
+			bm[off>>6] |= 1 << (off & 63)
+		}
+	case containerBitmap:
+		*bm = *c.bitmap
+	case containerRuns:
+		for _, run := range c.runs {
+			setBitRange(bm[:], uint32(run.lo), uint32(run.hi))
+		}
+	default:
+		panic(fmt.Errorf("BUG: unknown container kind %d", c.kind))
+	}
+}
+
+// eachRun calls fn for each maximal run of consecutive runes in the chunk,
+// as offsets from the start of the chunk.
+func (c *hybridChunk) eachRun(fn func(lo uint16, hi uint16)) {
+	switch c.kind {
+	case containerRuns:
+		for _, run := range c.runs {
+			fn(run.lo, run.hi)
+		}
+		return
+	case containerArray:
+		for i := 0; i < len(c.array); {
+			j := i + 1
+			for j < len(c.array) && c.array[j] == c.array[j-1]+1 {
+				j++
+			}
+			fn(c.array[i], c.array[j-1])
+			i = j
+		}
+		return
+	}
+
+	off := 0
+	for off < hybridChunkLen {
+		word := c.bitmap[off>>6] >> (off & 63)
+		if word == 0 {
+			off = (off | 63) + 1
+			continue
+		}
+		off += bits.TrailingZeros64(word)
+		lo := off
+		for off < hybridChunkLen {
+			word = ^c.bitmap[off>>6] >> (off & 63)
+			if word == 0 {
+				off = (off | 63) + 1
+				continue
+			}
+			off += bits.TrailingZeros64(word)
+			break
+		}
+		fn(uint16(lo), uint16(off-1))
+	}
+}
+
+// size returns the number of bytes taken by the chunk's container.
+func (c *hybridChunk) size() uint {
+	switch c.kind {
+	case containerArray:
+		return 2 * uint(len(c.array))
+	case containerBitmap:
+		return 8 * hybridBitmapWords
+	default:
+		return 4 * uint(len(c.runs))
+	}
+}
+
+func (c *hybridChunk) optimized() hybridChunk {
+	numRuns := uint(0)
+	c.eachRun(func(lo uint16, hi uint16) {
+		numRuns++
+	})
+	runsSize := 4 * numRuns
+	arraySize := 2 * uint(c.card)
+	bitmapSize := uint(8 * hybridBitmapWords)
+
+	out := hybridChunk{key: c.key, card: c.card}
+	switch {
+	case runsSize <= arraySize && runsSize <= bitmapSize:
+		out.kind = containerRuns
+		if c.kind == containerRuns {
+			out.runs = c.runs
+			break
+		}
+		out.runs = make([]hybridRun, 0, numRuns)
+		c.eachRun(func(lo uint16, hi uint16) {
+			out.runs = append(out.runs, hybridRun{lo, hi})
+		})
+	case arraySize <= bitmapSize:
+		out.kind = containerArray
+		if c.kind == containerArray {
+			out.array = c.array
+			break
+		}
+		out.array = make([]uint16, 0, c.card)
+		c.eachRun(func(lo uint16, hi uint16) {
+			for off := uint32(lo); off <= uint32(hi); off++ {
+				out.array = append(out.array, uint16(off))
+			}
+		})
+	default:
+		out.kind = containerBitmap
+		if c.kind == containerBitmap {
+			out.bitmap = c.bitmap
+			break
+		}
+		out.bitmap = new(hybridBitmap)
+		c.toBitmap(out.bitmap)
+	}
+	return out
+}
+
+// Optimize converts each chunk of the Hybrid to whichever container takes
+// the least space.  It must not be called concurrently with other methods.
+func (h *Hybrid) Optimize() *Hybrid {
+	for i := range h.chunks {
+		h.chunks[i] = h.chunks[i].optimized()
+	}
+	return h
+}
+
+// Footprint returns the number of bytes taken by the Hybrid's containers.
+func (h *Hybrid) Footprint() uint {
+	var sum uint
+	for i := range h.chunks {
+		sum += h.chunks[i].size()
+	}
+	return sum
+}
+
+func (h *Hybrid) findChunk(ch rune) *hybridChunk {
+	key := uint16(ch >> hybridChunkBits)
+	i, found := slices.BinarySearchFunc(h.chunks, key, func(c hybridChunk, key uint16) int {
+		return int(c.key) - int(key)
+	})
+	if !found {
+		return nil
+	}
+	return &h.chunks[i]
+}
+
+func (h *Hybrid) combine(other *Hybrid, op setOp) *Hybrid {
+	out := &Hybrid{}
+	a, b := h.chunks, other.chunks
+	i, j := 0, 0
+	var x, y, z hybridBitmap
+	for i < len(a) || j < len(b) {
+		// A chunk present in only one operand is either kept as it is or
+		// dropped, without going through a bitmap.
+		switch {
+		case j >= len(b) || (i < len(a) && a[i].key < b[j].key):
+			if op != opIntersect {
+				out.chunks = append(out.chunks, a[i])
+			}
+			i++
+			continue
+		case i >= len(a) || b[j].key < a[i].key:
+			if op == opUnion || op == opSymmetricDifference {
+				out.chunks = append(out.chunks, b[j])
+			}
+			j++
+			continue
+		}
+		x, y = hybridBitmap{}, hybridBitmap{}
+		key := a[i].key
+		a[i].toBitmap(&x)
+		b[j].toBitmap(&y)
+		i++
+		j++
+		for w := range z {
+			switch op {
+			case opUnion:
+				z[w] = x[w] | y[w]
+			case opIntersect:
+				z[w] = x[w] & y[w]
+			case opDifference:
+				z[w] = x[w] &^ y[w]
+			case opSymmetricDifference:
+				z[w] = x[w] ^ y[w]
+			default:
+				panic(fmt.Errorf("BUG: unknown Hybrid operation %d", op))
+			}
+		}
+		out.appendSmallest(key, &z)
+	}
+	return out
+}
+
+func (h *Hybrid) Union(other *Hybrid) *Hybrid {
+	return h.combine(other, opUnion)
+}
+
+func (h *Hybrid) Intersect(other *Hybrid) *Hybrid {
+	return h.combine(other, opIntersect)
+}
+
+func (h *Hybrid) Difference(other *Hybrid) *Hybrid {
+	return h.combine(other, opDifference)
+}
+
+func (h *Hybrid) SymmetricDifference(other *Hybrid) *Hybrid {
+	return h.combine(other, opSymmetricDifference)
+}
+
+func (h *Hybrid) Complement() *Hybrid {
+	out := &Hybrid{}
+	i := 0
+	var bm hybridBitmap
+	for key := uint16(0); key < hybridNumChunks; key++ {
+		bm = hybridBitmap{}
+		if i < len(h.chunks) && h.chunks[i].key == key {
+			h.chunks[i].toBitmap(&bm)
+			i++
+		}
+		for w := range bm {
+			bm[w] = ^bm[w]
+		}
+		out.appendSmallest(key, &bm)
+	}
+	return out
+}
+
+func (h *Hybrid) Count() uint {
+	var sum uint
+	for i := range h.chunks {
+		sum += uint(h.chunks[i].card)
+	}
+	return sum
+}
+
+// Set returns the Set of runes in the Hybrid.  Runs which meet at a chunk
+// boundary are joined into one pair.  The Set is built on the first call and
+// returned by every later one.
+func (h *Hybrid) Set() Set {
+	h.once.Do(func() {
+		var out []Pair
+		for i := range h.chunks {
+			c := &h.chunks[i]
+			base := rune(c.key) << hybridChunkBits
+			c.eachRun(func(lo uint16, hi uint16) {
+				out = appendMerged(out, Pair{base + rune(lo), base + rune(hi)})
+			})
+		}
+		h.set = makeSet(out)
+	})
+	return h.set
+}
+
+func (h *Hybrid) Len() uint {
+	return h.Set().Len()
+}
+
+func (h *Hybrid) At(index uint) Pair {
+	return h.Set().At(index)
+}
+
+func (h *Hybrid) Search(ch rune) (uint, bool) {
+	return searchSource(h, ch)
+}
+
+func (h *Hybrid) Contains(ch rune) bool {
+	if !isValidRune(ch) {
+		return false
+	}
+	c := h.findChunk(ch)
+	return c != nil && c.contains(uint16(ch&hybridChunkMask))
+}
+
+func (h *Hybrid) IsEmpty() bool {
+	return len(h.chunks) <= 0
+}
+
+func (h *Hybrid) IsFull() bool {
+	return h.Count() == unicode.MaxRune+1
+}
+
+func (h *Hybrid) Append(out []byte) []byte {
+	return appendSource(out, h)
+}
+
+func (h *Hybrid) String() string {
+	return toString(h)
+}
+
+func (*Hybrid) canonical() {}
+
+var (
+	_ Source          = (*Hybrid)(nil)
+	_ alwaysCanonical = (*Hybrid)(nil)
+	_ Appender        = (*Hybrid)(nil)
+	_ fmt.Stringer    = (*Hybrid)(nil)
+)
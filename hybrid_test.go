@@ -0,0 +1,80 @@
+package runeset
+
+import (
+	"math/rand"
+	"testing"
+	"unicode"
+)
+
+func TestHybrid(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	randomHybridPairs := func() PairList {
+		var out PairList
+		for i := rng.Intn(40); i > 0; i-- {
+			lo := rune(rng.Intn(3 * hybridChunkLen))
+			if rng.Intn(8) == 0 {
+				lo += unicode.MaxRune - 3*hybridChunkLen
+			}
+			width := rune(rng.Intn(4))
+			if rng.Intn(4) == 0 {
+				width = rune(rng.Intn(2 * hybridChunkLen))
+			}
+			out = append(out, Pair{lo, min(lo+width, unicode.MaxRune)})
+		}
+		return out
+	}
+
+	for iter := 0; iter < 300; iter++ {
+		a, b := Make(randomHybridPairs()), Make(randomHybridPairs())
+		x, y := NewHybrid(a), NewHybrid(b)
+
+		check := func(name string, expect Set, actual *Hybrid) {
+			t.Helper()
+			if !Equal(actual, expect) || actual.Count() != expect.Count() {
+				t.Fatalf("%s(%v, %v):\n\texpect: %v\n\tactual: %v", name, a, b, expect, actual)
+			}
+			for _, ch := range []rune{0, 1, hybridChunkLen - 1, hybridChunkLen, unicode.MaxRune} {
+				if actual.Contains(ch) != expect.Contains(ch) {
+					t.Fatalf("%s(%v, %v): Contains(%U): expect %v", name, a, b, ch, expect.Contains(ch))
+				}
+			}
+			expect.EachPair(func(pair Pair) bool {
+				if !actual.Contains(pair.Lo) || !actual.Contains(pair.Hi) || actual.Contains(pair.Hi+1) {
+					t.Fatalf("%s(%v, %v): wrong membership near %v", name, a, b, pair)
+				}
+				return true
+			})
+		}
+
+		check("NewHybrid", a, x)
+		check("Union", a.Union(b), x.Union(y))
+		check("Intersect", a.Intersect(b), x.Intersect(y))
+		check("Difference", a.Difference(b), x.Difference(y))
+		check("SymmetricDifference", a.SymmetricDifference(b), x.SymmetricDifference(y))
+		check("Complement", a.Complement(), x.Complement())
+
+		xo, yo := NewHybrid(a).Optimize(), NewHybrid(b).Optimize()
+		for _, h := range []*Hybrid{xo.Union(yo), xo.Intersect(yo), xo.Difference(yo), xo.SymmetricDifference(yo), x.Complement()} {
+			if actual, expect := h.Footprint(), NewHybrid(h).Optimize().Footprint(); actual != expect {
+				t.Fatalf("%v: combined chunks take %d bytes, but the smallest containers take %d", h, actual, expect)
+			}
+		}
+
+		before := x.Footprint()
+		check("Optimize", a, NewHybrid(a).Optimize())
+		if after := NewHybrid(a).Optimize().Footprint(); after > before {
+			t.Fatalf("%v: Optimize grew the footprint from %d to %d bytes", a, before, after)
+		}
+	}
+
+	full := NewHybrid(Full())
+	if !full.IsFull() || full.Len() != 1 {
+		t.Errorf("NewHybrid(Full()): got %v", full)
+	}
+	if actual := full.Optimize().Footprint(); actual != 4*hybridNumChunks {
+		t.Errorf("NewHybrid(Full()).Optimize(): expected one run per chunk, got %d bytes", actual)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { full.Set() }); allocs != 0 {
+		t.Errorf("NewHybrid(Full()).Set(): expected the Set to be built once, got %v allocations per call", allocs)
+	}
+}
@@ -0,0 +1,85 @@
+package runeset
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+// SetKey is a comparable form of a Set's contents, for use as a map key.
+// Two Sets have the same SetKey if and only if they are Equal.
+type SetKey string
+
+// Key returns the SetKey of the Set: its pairs in order, each as Lo then Hi
+// in 4-byte little-endian form.
+func (set Set) Key() SetKey {
+	n := set.Len()
+	buf := make([]byte, 0, 8*n)
+	for i := uint(0); i < n; i++ {
+		pair := set.At(i)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(pair.Lo))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(pair.Hi))
+	}
+	return SetKey(buf)
+}
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// Hash returns the 64-bit FNV-1a hash of the bytes of the Set's Key.  The
+// hash depends only on the runes in the Set, and will not change between
+// releases.
+func (set Set) Hash() uint64 {
+	h := uint64(fnvOffset64)
+	n := set.Len()
+	for i := uint(0); i < n; i++ {
+		pair := set.At(i)
+		for _, u := range [2]uint32{uint32(pair.Lo), uint32(pair.Hi)} {
+			for k := 0; k < 4; k++ {
+				h ^= uint64(byte(u >> (8 * k)))
+				h *= fnvPrime64
+			}
+		}
+	}
+	return h
+}
+
+// Interner deduplicates Sets, so that each distinct set of runes is stored
+// once.  The zero value is ready to use.  It is safe for concurrent use.
+type Interner struct {
+	mu   sync.Mutex
+	sets map[SetKey]Set
+}
+
+func NewInterner() *Interner {
+	return &Interner{}
+}
+
+// Intern returns the first Set given to the Interner that is Equal to set,
+// remembering set if there is none.
+func (in *Interner) Intern(set Set) Set {
+	key := set.Key()
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if existing, found := in.sets[key]; found {
+		return existing
+	}
+	if in.sets == nil {
+		in.sets = make(map[SetKey]Set)
+	}
+	in.sets[key] = set
+	return set
+}
+
+// Len returns the number of distinct Sets held by the Interner.
+func (in *Interner) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.sets)
+}
+
+// gBuiltinInterner deduplicates the classes built by the registries.
+var gBuiltinInterner Interner
@@ -0,0 +1,34 @@
+package runeset
+
+import (
+	"testing"
+)
+
+func TestInterner(t *testing.T) {
+	a := Make(Pair{'a', 'z'}, Pair{'0', '9'})
+	b := NewBuilder().AddRange('0', '9').AddRange('a', 'z').Build()
+	c := Make(Pair{'a', 'z'})
+
+	if a.Key() != b.Key() || a.Key() == c.Key() || a.Hash() != b.Hash() || a.Hash() == c.Hash() {
+		t.Errorf("Key/Hash: equal Sets must match and unequal Sets should differ")
+	}
+	if a.Compact().Key() != a.Key() || a.Clip('0', 'z').Set().Hash() != a.Hash() {
+		t.Errorf("Key/Hash: must not depend on how a Set is stored")
+	}
+	if actual, expect := Empty().Hash(), uint64(14695981039346656037); actual != expect {
+		t.Errorf("Empty().Hash(): expect %d, got %d", expect, actual)
+	}
+	if actual, expect := Make(Pair{0, 0}).Hash(), uint64(0xa8c7f832281a39c5); actual != expect {
+		t.Errorf("Hash of [\\0]: expect %#x, got %#x", expect, actual)
+	}
+
+	var in Interner
+	x, y, z := in.Intern(a), in.Intern(b), in.Intern(c)
+	if !x.Equal(a) || !y.Equal(a) || !z.Equal(c) || in.Len() != 2 {
+		t.Errorf("Intern: equal Sets were not deduplicated")
+	}
+	in.Intern(Make(Pair{'a', 'z'}))
+	if in.Len() != 2 {
+		t.Errorf("Intern: a Set equal to an interned one was added again")
+	}
+}
@@ -0,0 +1,92 @@
+package runeset
+
+// The iterators in this file visit the pairs of any Source in the order
+// given by At, and stop early if the callback returns false.  The Within
+// variants visit only the parts of each pair that lie inside [lo, hi].  Set
+// also offers them as methods.
+
+func EachPair[S Source](src S, fn func(Pair) bool) {
+	n := src.Len()
+	for i := uint(0); i < n; i++ {
+		if !fn(src.At(i)) {
+			return
+		}
+	}
+}
+
+func EachPairReverse[S Source](src S, fn func(Pair) bool) {
+	for i := src.Len(); i > 0; i-- {
+		if !fn(src.At(i - 1)) {
+			return
+		}
+	}
+}
+
+func EachPairWithin[S Source](src S, lo rune, hi rune, fn func(Pair) bool) {
+	n := src.Len()
+	i := uint(0)
+	sorted := knownCanonical(src)
+	if sorted {
+		i, _ = searchSource(src, lo)
+	}
+	for ; i < n; i++ {
+		pair := src.At(i)
+		if pair.Hi < lo || pair.Lo > hi {
+			if sorted && pair.Lo > hi {
+				return
+			}
+			continue
+		}
+		pair.Lo = max(pair.Lo, lo)
+		pair.Hi = min(pair.Hi, hi)
+		if !fn(pair) {
+			return
+		}
+	}
+}
+
+func EachRune[S Source](src S, fn func(rune) bool) {
+	EachPair(src, func(pair Pair) bool {
+		return runesOf(pair, fn)
+	})
+}
+
+func EachRuneReverse[S Source](src S, fn func(rune) bool) {
+	EachPairReverse(src, func(pair Pair) bool {
+		for ch := pair.Hi; ch >= pair.Lo; ch-- {
+			if !fn(ch) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func EachRuneWithin[S Source](src S, lo rune, hi rune, fn func(rune) bool) {
+	EachPairWithin(src, lo, hi, func(pair Pair) bool {
+		return runesOf(pair, fn)
+	})
+}
+
+func runesOf(pair Pair, fn func(rune) bool) bool {
+	for ch := pair.Lo; ch <= pair.Hi; ch++ {
+		if !fn(ch) {
+			return false
+		}
+	}
+	return true
+}
+
+// All returns a sequence of the pairs of src, for use with range-over-func.
+func All[S Source](src S) func(yield func(Pair) bool) {
+	return func(yield func(Pair) bool) {
+		EachPair(src, yield)
+	}
+}
+
+// Runes returns a sequence of the runes of src, for use with range-over-func.
+func Runes[S Source](src S) func(yield func(rune) bool) {
+	return func(yield func(rune) bool) {
+		EachRune(src, yield)
+	}
+}
@@ -0,0 +1,80 @@
+package runeset
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSet_Iteration(t *testing.T) {
+	set := ForClass("ascii.xdigit")
+
+	var runes []rune
+	set.EachRune(func(ch rune) bool {
+		runes = append(runes, ch)
+		return true
+	})
+	if actual, expect := string(runes), "0123456789ABCDEFabcdef"; actual != expect {
+		t.Errorf("EachRune: expect %q, got %q", expect, actual)
+	}
+
+	runes = runes[:0]
+	set.EachRuneReverse(func(ch rune) bool {
+		runes = append(runes, ch)
+		return ch != 'A'
+	})
+	if actual, expect := string(runes), "fedcbaFEDCBA"; actual != expect {
+		t.Errorf("EachRuneReverse: expect %q, got %q", expect, actual)
+	}
+
+	runes = runes[:0]
+	set.EachRuneWithin('5', 'B', func(ch rune) bool {
+		runes = append(runes, ch)
+		return true
+	})
+	if actual, expect := string(runes), "56789AB"; actual != expect {
+		t.Errorf("EachRuneWithin: expect %q, got %q", expect, actual)
+	}
+
+	var pairs PairList
+	set.All()(func(pair Pair) bool {
+		pairs = append(pairs, pair)
+		return len(pairs) < 2
+	})
+	if actual, expect := pairs.String(), `[0-9A-F]`; actual != expect {
+		t.Errorf("All: expect %q, got %q", expect, actual)
+	}
+
+	pairs = pairs[:0]
+	EachPairWithin(PairList{{'x', 'z'}, {'a', 'c'}}, 'b', 'y', func(pair Pair) bool {
+		pairs = append(pairs, pair)
+		return true
+	})
+	if actual, expect := fmt.Sprint([]Pair(pairs)), `[[x-y] [b-c]]`; actual != expect {
+		t.Errorf("EachPairWithin: expect %q, got %q", expect, actual)
+	}
+
+	runes = runes[:0]
+	Runes(NewBuilder().AddRange('x', 'z').AddRune('a'))(func(ch rune) bool {
+		runes = append(runes, ch)
+		return true
+	})
+	if actual, expect := string(runes), "axyz"; actual != expect {
+		t.Errorf("Runes: expect %q, got %q", expect, actual)
+	}
+
+	letters := ForClass("L")
+	count := 0
+	allocs := testing.AllocsPerRun(10, func() {
+		count = 0
+		letters.EachRune(func(ch rune) bool {
+			count++
+			return true
+		})
+	})
+	if allocs != 0 {
+		t.Errorf("EachRune: expected no allocations, got %v", allocs)
+	}
+	if count < 100000 {
+		t.Errorf("EachRune: visited only %d letters", count)
+	}
+}
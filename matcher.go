@@ -0,0 +1,141 @@
+package runeset
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Matcher is a Set compiled into a three-stage lookup table, for membership
+// tests in constant time.  Bits 20..12 of a rune select a block of the
+// middle stage, bits 11..8 select a leaf within that block, and bits 7..0
+// select a bit within that leaf.  Identical leaves and identical blocks are
+// stored only once.
+type Matcher struct {
+	set    Set
+	top    [(unicode.MaxRune + 1) >> 12]uint16
+	mid    []uint16
+	leaves [][4]uint64
+}
+
+const (
+	matcherLeafBits  = 8
+	matcherBlockBits = 4
+	matcherBlockLen  = 1 << matcherBlockBits
+)
+
+func (set Set) Compile() *Matcher {
+	m := &Matcher{set: set}
+
+	bits := make([]uint64, (unicode.MaxRune+1)>>6)
+	set.EachPair(func(pair Pair) bool {
+		setBitRange(bits, uint32(pair.Lo), uint32(pair.Hi))
+		return true
+	})
+
+	leafIndex := make(map[[4]uint64]uint16)
+	blockIndex := make(map[[matcherBlockLen]uint16]uint16)
+	for t := range m.top {
+		var block [matcherBlockLen]uint16
+		for k := range block {
+			start := ((t << matcherBlockBits) | k) << (matcherLeafBits - 6)
+			var leaf [4]uint64
+			copy(leaf[:], bits[start:start+4])
+			index, found := leafIndex[leaf]
+			if !found {
+				index = uint16(len(m.leaves))
+				leafIndex[leaf] = index
+				m.leaves = append(m.leaves, leaf)
+			}
+			block[k] = index
+		}
+		index, found := blockIndex[block]
+		if !found {
+			index = uint16(len(m.mid) >> matcherBlockBits)
+			blockIndex[block] = index
+			m.mid = append(m.mid, block[:]...)
+		}
+		m.top[t] = index
+	}
+	return m
+}
+
+func setBitRange(bits []uint64, lo uint32, hi uint32) {
+	for lo <= hi {
+		word, bit := lo>>6, lo&63
+		if bit == 0 && hi-lo >= 63 {
+			bits[word] = ^uint64(0)
+			lo += 64
+			continue
+		}
+		bits[word] |= 1 << bit
+		lo++
+	}
+}
+
+func (m *Matcher) ContainsRune(ch rune) bool {
+	u := uint32(ch)
+	if u > unicode.MaxRune {
+		return false
+	}
+	block := uint32(m.top[u>>12]) << matcherBlockBits
+	leaf := m.mid[block|((u>>matcherLeafBits)&(matcherBlockLen-1))]
+	return (m.leaves[leaf][(u>>6)&3] & (1 << (u & 63))) != 0
+}
+
+// ContainsByte reports whether the ASCII character b is in the Set.  Bytes
+// outside of ASCII are never contained, as they are not runes by themselves.
+func (m *Matcher) ContainsByte(b byte) bool {
+	return b < utf8.RuneSelf && m.ContainsRune(rune(b))
+}
+
+// Set returns the Set that was compiled into the Matcher.
+func (m *Matcher) Set() Set {
+	return m.set
+}
+
+// Footprint returns the number of bytes taken by the Matcher's lookup tables.
+func (m *Matcher) Footprint() uint {
+	return 2*uint(len(m.top)+len(m.mid)) + 32*uint(len(m.leaves))
+}
+
+func (m *Matcher) Len() uint {
+	return m.set.Len()
+}
+
+func (m *Matcher) At(index uint) Pair {
+	return m.set.At(index)
+}
+
+func (m *Matcher) Search(ch rune) (uint, bool) {
+	return m.set.Search(ch)
+}
+
+func (m *Matcher) Contains(ch rune) bool {
+	return m.ContainsRune(ch)
+}
+
+func (m *Matcher) IsEmpty() bool {
+	return m.set.IsEmpty()
+}
+
+func (m *Matcher) IsFull() bool {
+	return m.set.IsFull()
+}
+
+func (m *Matcher) Append(out []byte) []byte {
+	return m.set.Append(out)
+}
+
+func (m *Matcher) String() string {
+	return m.set.String()
+}
+
+func (*Matcher) canonical() {}
+
+var (
+	_ Source          = (*Matcher)(nil)
+	_ alwaysCanonical = (*Matcher)(nil)
+	_ Appender        = (*Matcher)(nil)
+	_ fmt.Stringer    = (*Matcher)(nil)
+)
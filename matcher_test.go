@@ -0,0 +1,46 @@
+package runeset
+
+import (
+	"math/rand"
+	"testing"
+	"unicode"
+)
+
+func TestMatcher(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	for iter := 0; iter < 200; iter++ {
+		var b Builder
+		b.Reset()
+		for i := rng.Intn(8); i > 0; i-- {
+			lo := rune(rng.Intn(unicode.MaxRune + 1))
+			b.AddRange(lo, min(lo+rune(rng.Intn(0x400)), unicode.MaxRune))
+		}
+		set := b.Build()
+		m := set.Compile()
+		set.EachPair(func(pair Pair) bool {
+			for _, ch := range []rune{pair.Lo - 1, pair.Lo, pair.Hi, pair.Hi + 1} {
+				if actual, expect := m.ContainsRune(ch), set.Contains(ch); actual != expect {
+					t.Fatalf("%v: ContainsRune(%U): expect %v, got %v", set, ch, expect, actual)
+				}
+			}
+			return true
+		})
+		if !Equal(m, set) {
+			t.Fatalf("%v: Matcher is not Equal to its Set", set)
+		}
+	}
+
+	letters := ForClass("L")
+	m := letters.Compile()
+	for ch := rune(-1); ch <= unicode.MaxRune+1; ch++ {
+		if actual, expect := m.ContainsRune(ch), letters.Contains(ch); actual != expect {
+			t.Fatalf("L: ContainsRune(%U): expect %v, got %v", ch, expect, actual)
+		}
+	}
+	if size := m.Footprint(); size > 8<<10 {
+		t.Errorf("L: Matcher tables take %d bytes", size)
+	}
+	if !m.ContainsByte('q') || m.ContainsByte('7') || m.ContainsByte(0xe9) {
+		t.Errorf("L: wrong ContainsByte results")
+	}
+}
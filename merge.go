@@ -0,0 +1,322 @@
+package runeset
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// The functions in this file implement set algebra as sorted sweeps over
+// canonical Sources: Sources whose pairs are valid, sorted, and neither
+// overlapping nor adjacent.  Each appends its canonical result to out.
+
+// alwaysCanonical is implemented by the Source types whose pairs are
+// canonical by construction.
+type alwaysCanonical interface {
+	canonical()
+}
+
+// knownCanonical reports whether src is of a type whose pairs are always
+// canonical, without looking at them.
+func knownCanonical(src any) bool {
+	_, ok := src.(alwaysCanonical)
+	return ok
+}
+
+// isCanonical reports whether src is a canonical Source.
+func isCanonical(src Source) bool {
+	if knownCanonical(src) {
+		return true
+	}
+	n := src.Len()
+	var prev Pair
+	for i := uint(0); i < n; i++ {
+		pair := sourceAt(src, i)
+		if !pair.IsValid() {
+			return false
+		}
+		if i > 0 && prev.Hi+1 >= pair.Lo {
+			return false
+		}
+		prev = pair
+	}
+	return true
+}
+
+// canonicalSource returns src if it is canonical, or else a canonical copy
+// of its pairs.  Like a Builder, it panics if any pair is invalid.
+func canonicalSource(src Source) Source {
+	var b Builder
+	return b.normalize(src)
+}
+
+// normalizeList sorts and merges the valid pairs in list, discarding the
+// invalid ones.  It modifies list in place.
+func normalizeList(list []Pair) []Pair {
+	// phase one: remove invalid pairs
+	n := 0
+	for _, pair := range list {
+		if pair.IsValid() {
+			list[n] = pair
+			n++
+		}
+	}
+	list = list[:n]
+
+	// phase two: sort
+	PairList(list).Sort()
+
+	// phase three: merge overlapping/adjacent
+	out := list[:0]
+	for _, pair := range list {
+		out = appendMerged(out, pair)
+	}
+	return out
+}
+
+// verifyList panics if list is not canonical.
+func verifyList(list []Pair) {
+	for i, pair := range list {
+		pair.AssertValid()
+		if i == 0 {
+			continue
+		}
+		prev := list[i-1]
+		if prev.Lo >= pair.Lo {
+			panic(fmt.Errorf("BUG: [%d] %v >= [%d] %v", i-1, prev, i, pair))
+		}
+		if prev.Hi+1 >= pair.Lo {
+			panic(fmt.Errorf("BUG: [%d] %v touches [%d] %v and should have been merged with it", i-1, prev, i, pair))
+		}
+	}
+}
+
+// appendMerged appends pair to out, merging it into the last pair of out if
+// they overlap or touch.  The pairs must arrive in order of Lo.
+func appendMerged(out []Pair, pair Pair) []Pair {
+	if n := len(out); n > 0 {
+		last := &out[n-1]
+		if last.Hi+1 >= pair.Lo {
+			if pair.Hi > last.Hi {
+				last.Hi = pair.Hi
+			}
+			return out
+		}
+	}
+	return append(out, pair)
+}
+
+func unionSweep(out []Pair, a Source, b Source) []Pair {
+	i, n := uint(0), a.Len()
+	j, m := uint(0), b.Len()
+	for i < n && j < m {
+		p := a.At(i)
+		q := b.At(j)
+		if p.Lo <= q.Lo {
+			out = appendMerged(out, p)
+			i++
+		} else {
+			out = appendMerged(out, q)
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out = appendMerged(out, a.At(i))
+	}
+	for ; j < m; j++ {
+		out = appendMerged(out, b.At(j))
+	}
+	return out
+}
+
+func intersectSweep(out []Pair, a Source, b Source) []Pair {
+	i, n := uint(0), a.Len()
+	j, m := uint(0), b.Len()
+	for i < n && j < m {
+		p := a.At(i)
+		q := b.At(j)
+		lo := max(p.Lo, q.Lo)
+		hi := min(p.Hi, q.Hi)
+		if lo <= hi {
+			out = append(out, Pair{lo, hi})
+		}
+		if p.Hi <= q.Hi {
+			i++
+		}
+		if q.Hi <= p.Hi {
+			j++
+		}
+	}
+	return out
+}
+
+func differenceSweep(out []Pair, a Source, b Source) []Pair {
+	j, m := uint(0), b.Len()
+	n := a.Len()
+	for i := uint(0); i < n; i++ {
+		p := a.At(i)
+		lo := p.Lo
+		for j < m {
+			q := b.At(j)
+			if q.Hi < lo {
+				j++
+				continue
+			}
+			if q.Lo > p.Hi {
+				break
+			}
+			if q.Lo > lo {
+				out = append(out, Pair{lo, q.Lo - 1})
+			}
+			if q.Hi >= p.Hi {
+				lo = p.Hi + 1
+				break
+			}
+			lo = q.Hi + 1
+			j++
+		}
+		if lo <= p.Hi {
+			out = append(out, Pair{lo, p.Hi})
+		}
+	}
+	return out
+}
+
+// symmetricSweep treats each Source as a sorted list of boundaries, where
+// membership toggles.  Boundaries present in both Sources cancel out.
+func symmetricSweep(out []Pair, a Source, b Source) []Pair {
+	boundary := func(src Source, k uint) rune {
+		pair := src.At(k >> 1)
+		if (k & 1) == 0 {
+			return pair.Lo
+		}
+		return pair.Hi + 1
+	}
+
+	i, n := uint(0), 2*a.Len()
+	j, m := uint(0), 2*b.Len()
+	inside := false
+	var lo rune
+	for i < n || j < m {
+		var x rune
+		switch {
+		case j >= m:
+			x = boundary(a, i)
+			i++
+		case i >= n:
+			x = boundary(b, j)
+			j++
+		default:
+			p := boundary(a, i)
+			q := boundary(b, j)
+			if p == q {
+				i++
+				j++
+				continue
+			}
+			if p < q {
+				x = p
+				i++
+			} else {
+				x = q
+				j++
+			}
+		}
+		if inside {
+			out = append(out, Pair{lo, x - 1})
+		} else {
+			lo = x
+		}
+		inside = !inside
+	}
+	return out
+}
+
+func complementSweep(out []Pair, a Source) []Pair {
+	lo := rune(0)
+	n := a.Len()
+	for i := uint(0); i < n; i++ {
+		p := a.At(i)
+		if p.Lo > lo {
+			out = append(out, Pair{lo, p.Lo - 1})
+		}
+		lo = p.Hi + 1
+	}
+	if lo <= unicode.MaxRune {
+		out = append(out, Pair{lo, unicode.MaxRune})
+	}
+	return out
+}
+
+// unionAll is a k-way merge of its inputs, using a binary heap of cursors
+// keyed on the Lo of each cursor's current pair.
+func unionAll(out []Pair, inputs []Source) []Pair {
+	switch len(inputs) {
+	case 0:
+		return out
+	case 1:
+		return unionSweep(out, inputs[0], PairList(nil))
+	case 2:
+		return unionSweep(out, inputs[0], inputs[1])
+	}
+
+	h := make(cursorHeap, 0, len(inputs))
+	for _, src := range inputs {
+		if n := src.Len(); n > 0 {
+			h = append(h, mergeCursor{src: src, n: n, pair: src.At(0)})
+		}
+	}
+	for k := len(h)/2 - 1; k >= 0; k-- {
+		h.down(k)
+	}
+	for len(h) > 0 {
+		c := &h[0]
+		out = appendMerged(out, c.pair)
+		c.i++
+		if c.i < c.n {
+			c.pair = c.src.At(c.i)
+		} else {
+			last := len(h) - 1
+			h[0] = h[last]
+			h = h[:last]
+		}
+		h.down(0)
+	}
+	return out
+}
+
+type mergeCursor struct {
+	src  Source
+	n    uint
+	i    uint
+	pair Pair
+}
+
+type cursorHeap []mergeCursor
+
+func (h cursorHeap) down(k int) {
+	n := len(h)
+	for {
+		least := k
+		l := 2*k + 1
+		r := l + 1
+		if l < n && h[l].pair.Lo < h[least].pair.Lo {
+			least = l
+		}
+		if r < n && h[r].pair.Lo < h[least].pair.Lo {
+			least = r
+		}
+		if least == k {
+			return
+		}
+		h[k], h[least] = h[least], h[k]
+		k = least
+	}
+}
+
+func totalLen(inputs []Source) uint {
+	var sum uint
+	for _, src := range inputs {
+		sum += src.Len()
+	}
+	return sum
+}
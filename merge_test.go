@@ -0,0 +1,152 @@
+package runeset
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// modelSet is a naive model of a Set over a small domain, plus MaxRune.
+type modelSet map[rune]bool
+
+const modelDomain = 96
+
+func randomPairs(rng *rand.Rand) PairList {
+	n := rng.Intn(6)
+	out := make(PairList, 0, n)
+	for i := 0; i < n; i++ {
+		lo := rune(rng.Intn(modelDomain))
+		hi := lo + rune(rng.Intn(12))
+		if hi >= modelDomain {
+			hi = unicode.MaxRune
+		}
+		out = append(out, Pair{lo, hi})
+	}
+	return out
+}
+
+func modelOf(src Source) modelSet {
+	m := make(modelSet)
+	n := src.Len()
+	for i := uint(0); i < n; i++ {
+		pair := src.At(i)
+		for ch := pair.Lo; ch <= pair.Hi && ch < modelDomain; ch++ {
+			m[ch] = true
+		}
+		if pair.Hi == unicode.MaxRune {
+			m[unicode.MaxRune] = true
+		}
+	}
+	return m
+}
+
+// String writes the model in the bracket notation of Set.String, without
+// going through any of the code under test.
+func (m modelSet) String() string {
+	var runs []Pair
+	add := func(lo rune, hi rune) {
+		if n := len(runs); n > 0 && runs[n-1].Hi+1 == lo {
+			runs[n-1].Hi = hi
+			return
+		}
+		runs = append(runs, Pair{lo, hi})
+	}
+	for ch := rune(0); ch < modelDomain; ch++ {
+		if m[ch] {
+			add(ch, ch)
+		}
+	}
+	if m[unicode.MaxRune] {
+		add(modelDomain, unicode.MaxRune)
+	}
+
+	switch {
+	case len(runs) == 0:
+		return "!."
+	case len(runs) == 1 && runs[0] == Pair{0, unicode.MaxRune}:
+		return "."
+	}
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for _, run := range runs {
+		sb.WriteString(modelRune(run.Lo))
+		if run.Lo != run.Hi {
+			sb.WriteByte('-')
+			sb.WriteString(modelRune(run.Hi))
+		}
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// modelRune writes a rune of the model domain, or MaxRune, as Set.String
+// does.
+func modelRune(ch rune) string {
+	switch {
+	case ch >= '0' && ch <= '9', ch >= 'A' && ch <= 'Z', ch >= 'a' && ch <= 'z':
+		return string(ch)
+	case ch == 0:
+		return `\0`
+	case ch == '\t':
+		return `\t`
+	case ch == '\n':
+		return `\n`
+	case ch == '\v':
+		return `\v`
+	case ch == '\f':
+		return `\f`
+	case ch == '\r':
+		return `\r`
+	case ch == unicode.MaxRune:
+		return `\z`
+	default:
+		return fmt.Sprintf(`\x%02x`, ch)
+	}
+}
+
+func TestBuilder_Random(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 2000; iter++ {
+		base := randomPairs(rng)
+		x := randomPairs(rng)
+		y := Make(randomPairs(rng))
+		z := RuneList{rune(rng.Intn(modelDomain)), rune(rng.Intn(modelDomain))}
+
+		mb, mx, my, mz := modelOf(base), modelOf(x), modelOf(y), modelOf(z)
+
+		expect := make(modelSet)
+		for ch := range mb {
+			expect[ch] = true
+		}
+		for _, m := range []modelSet{mx, my, mz} {
+			for ch := range m {
+				expect[ch] = true
+			}
+		}
+		if actual := NewBuilder().Add(base).Add(x, y, z).String(); actual != expect.String() {
+			t.Fatalf("Add(%v, %v, %v, %v):\n\texpect: %v\n\tactual: %v", base, x, y, z, expect, actual)
+		}
+
+		expect = make(modelSet)
+		for ch := range mb {
+			if !mx[ch] && !my[ch] && !mz[ch] {
+				expect[ch] = true
+			}
+		}
+		if actual := NewBuilder().Add(base).Remove(x, y, z).String(); actual != expect.String() {
+			t.Fatalf("Remove(%v; %v, %v, %v):\n\texpect: %v\n\tactual: %v", base, x, y, z, expect, actual)
+		}
+
+		expect = make(modelSet)
+		for ch := range mb {
+			if mx[ch] && my[ch] {
+				expect[ch] = true
+			}
+		}
+		if actual := NewBuilder().Add(base).Intersect(x, y).String(); actual != expect.String() {
+			t.Fatalf("Intersect(%v; %v, %v):\n\texpect: %v\n\tactual: %v", base, x, y, expect, actual)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package runeset
+
+import (
+	"sync"
+	"unicode"
+)
+
+// setInfo holds data derived from a Set's pairs, shared by every copy of
+// the Set.  The Latin-1 bitmap is computed when the Set is made; the prefix
+// counts are computed on first use.  A compact Set has no list, and keeps
+// its pairs here instead.
+type setInfo struct {
+	latin1 [4]uint64
+	once   sync.Once
+	prefix []uint32
+	packed *packedPairs
+}
+
+func makeSet(list []Pair) Set {
+	if len(list) <= 0 {
+		return Set{}
+	}
+	return Set{list: list}.withNewInfo()
+}
+
+// withNewInfo returns the Set with a freshly computed setInfo.  It must be
+// called whenever a Set's pairs change.
+func (set Set) withNewInfo() Set {
+	info := &setInfo{packed: set.packed()}
+	n := set.Len()
+	for i := uint(0); i < n; i++ {
+		pair := set.At(i)
+		if pair.Lo > 0xff {
+			break
+		}
+		for ch := pair.Lo; ch <= min(pair.Hi, 0xff); ch++ {
+			info.latin1[ch>>6] |= 1 << (ch & 63)
+		}
+	}
+	set.info = info
+	return set
+}
+
+// prefixCounts returns the prefix sums of the pair sizes: prefix[i] is the
+// number of runes in the first i pairs, so prefix[set.Len()] is the
+// cardinality.
+func (set Set) prefixCounts() []uint32 {
+	if set.info == nil {
+		return gEmptyPrefix[:]
+	}
+	set.info.once.Do(func() {
+		n := set.Len()
+		prefix := make([]uint32, n+1)
+		var sum uint32
+		for i := uint(0); i < n; i++ {
+			pair := set.At(i)
+			sum += uint32(pair.Hi-pair.Lo) + 1
+			prefix[i+1] = sum
+		}
+		set.info.prefix = prefix
+	})
+	return set.info.prefix
+}
+
+var gEmptyPrefix = [1]uint32{0}
+
+// Count returns the number of runes in the Set.
+func (set Set) Count() uint {
+	prefix := set.prefixCounts()
+	return uint(prefix[len(prefix)-1])
+}
+
+// Rank returns the number of runes in the Set which are less than ch.
+func (set Set) Rank(ch rune) uint {
+	switch {
+	case ch < 0:
+		return 0
+	case ch > unicode.MaxRune:
+		return set.Count()
+	}
+	prefix := set.prefixCounts()
+	index, found := set.Search(ch)
+	rank := uint(prefix[index])
+	if found {
+		rank += uint(ch - set.At(index).Lo)
+	}
+	return rank
+}
+
+// Select returns the rune with the given rank, i.e. the n'th rune of the Set
+// counting from zero.  It returns false if n >= set.Count().
+func (set Set) Select(n uint) (rune, bool) {
+	prefix := set.prefixCounts()
+	if n >= uint(prefix[len(prefix)-1]) {
+		return 0, false
+	}
+	i, j := uint(0), set.Len()
+	for i < j {
+		k := (i + j) >> 1
+		if uint(prefix[k+1]) <= n {
+			i = k + 1
+		} else {
+			j = k
+		}
+	}
+	return set.At(i).Lo + rune(n-uint(prefix[i])), true
+}
+
+// Next returns the least rune in the Set which is greater than ch.
+func (set Set) Next(ch rune) (rune, bool) {
+	switch {
+	case ch < 0:
+		return set.Min()
+	case ch >= unicode.MaxRune:
+		return 0, false
+	}
+	ch++
+	index, found := set.Search(ch)
+	if found {
+		return ch, true
+	}
+	if index < set.Len() {
+		return set.At(index).Lo, true
+	}
+	return 0, false
+}
+
+// Prev returns the greatest rune in the Set which is less than ch.
+func (set Set) Prev(ch rune) (rune, bool) {
+	switch {
+	case ch <= 0:
+		return 0, false
+	case ch > unicode.MaxRune:
+		return set.Max()
+	}
+	ch--
+	index, found := set.Search(ch)
+	if found {
+		return ch, true
+	}
+	if index > 0 {
+		return set.At(index - 1).Hi, true
+	}
+	return 0, false
+}
+
+func (set Set) Min() (rune, bool) {
+	if set.IsEmpty() {
+		return 0, false
+	}
+	return set.At(0).Lo, true
+}
+
+func (set Set) Max() (rune, bool) {
+	if set.IsEmpty() {
+		return 0, false
+	}
+	return set.At(set.Len() - 1).Hi, true
+}
@@ -0,0 +1,81 @@
+package runeset
+
+import (
+	"math/rand"
+	"testing"
+	"unicode"
+)
+
+func TestSet_Order(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	for iter := 0; iter < 500; iter++ {
+		var b Builder
+		b.Reset()
+		for i := rng.Intn(6); i > 0; i-- {
+			lo := rune(rng.Intn(modelDomain))
+			b.AddRange(lo, min(lo+rune(rng.Intn(12)), modelDomain-1))
+		}
+		set := b.Build()
+
+		var members []rune
+		set.EachRune(func(ch rune) bool {
+			members = append(members, ch)
+			return true
+		})
+		if actual := set.Count(); actual != uint(len(members)) {
+			t.Fatalf("%v: Count: expect %d, got %d", set, len(members), actual)
+		}
+		for n, ch := range members {
+			if actual, ok := set.Select(uint(n)); !ok || actual != ch {
+				t.Fatalf("%v: Select(%d): expect %q, got %q, %v", set, n, ch, actual, ok)
+			}
+		}
+		if _, ok := set.Select(uint(len(members))); ok {
+			t.Fatalf("%v: Select(%d): expect false", set, len(members))
+		}
+
+		rank := uint(0)
+		for ch := rune(-1); ch <= modelDomain; ch++ {
+			if actual := set.Rank(ch); actual != rank {
+				t.Fatalf("%v: Rank(%q): expect %d, got %d", set, ch, rank, actual)
+			}
+			if set.Contains(ch) {
+				rank++
+			}
+
+			next, nextOK := rune(0), false
+			for _, m := range members {
+				if m > ch {
+					next, nextOK = m, true
+					break
+				}
+			}
+			if actual, ok := set.Next(ch); ok != nextOK || actual != next {
+				t.Fatalf("%v: Next(%q): expect %q, %v, got %q, %v", set, ch, next, nextOK, actual, ok)
+			}
+
+			prev, prevOK := rune(0), false
+			for _, m := range members {
+				if m < ch {
+					prev, prevOK = m, true
+				}
+			}
+			if actual, ok := set.Prev(ch); ok != prevOK || actual != prev {
+				t.Fatalf("%v: Prev(%q): expect %q, %v, got %q, %v", set, ch, prev, prevOK, actual, ok)
+			}
+		}
+	}
+
+	if actual, expect := Full().Count(), uint(unicode.MaxRune+1); actual != expect {
+		t.Errorf("Full().Count(): expect %d, got %d", expect, actual)
+	}
+	if lo, ok := Full().Min(); !ok || lo != 0 {
+		t.Errorf("Full().Min(): expect 0, got %q, %v", lo, ok)
+	}
+	if hi, ok := Full().Max(); !ok || hi != unicode.MaxRune {
+		t.Errorf("Full().Max(): expect MaxRune, got %q, %v", hi, ok)
+	}
+	if _, ok := Empty().Min(); ok || Empty().Count() != 0 || Empty().Rank('a') != 0 {
+		t.Errorf("Empty(): expected no members")
+	}
+}
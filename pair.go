@@ -16,23 +16,37 @@ func MakePair(lo rune, hi rune) Pair {
 	return pair
 }
 
+func NewPair(lo rune, hi rune) (Pair, error) {
+	pair := Pair{Lo: lo, Hi: hi}
+	if err := pair.Validate(); err != nil {
+		return Pair{}, err
+	}
+	return pair, nil
+}
+
 func (pair Pair) IsValid() bool {
 	return isValidPair(pair.Lo, pair.Hi)
 }
 
-func (pair Pair) AssertValid() {
+func (pair Pair) Validate() error {
 	if pair.IsValid() {
-		return
+		return nil
 	}
+	lo := uint32(pair.Lo)
+	hi := uint32(pair.Hi)
 	if !isValidRune(pair.Lo) {
-		panic(fmt.Errorf("BUG: in %v, lower bound is not a valid Unicode code point", pair))
+		return fmt.Errorf("in pair U+%04X..U+%04X, lower bound: %w", lo, hi, InvalidRuneError{pair.Lo})
 	}
 	if !isValidRune(pair.Hi) {
-		panic(fmt.Errorf("BUG: in %v, upper bound is not a valid Unicode code point", pair))
+		return fmt.Errorf("in pair U+%04X..U+%04X, upper bound: %w", lo, hi, InvalidRuneError{pair.Hi})
+	}
+	return InvertedPairError{pair}
+}
+
+func (pair Pair) AssertValid() {
+	if err := pair.Validate(); err != nil {
+		panic(fmt.Errorf("BUG: %w", err))
 	}
-	lo := uint32(pair.Lo)
-	hi := uint32(pair.Hi)
-	panic(fmt.Errorf("BUG: in %v, lower bound U+%04X exceeds upper bound U+%04X", pair, lo, hi))
 }
 
 func (pair Pair) Len() uint {
@@ -0,0 +1,238 @@
+package runeset
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+type ParseError struct {
+	Input  string
+	Offset int
+	Err    error
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse %q at byte %d: %v", err.Input, err.Offset, err.Err)
+}
+
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
+
+var _ error = (*ParseError)(nil)
+
+func ParseSet(str string) (Set, error) {
+	var b Builder
+	if err := b.Reset().AddParsed(str); err != nil {
+		return Empty(), err
+	}
+	return b.Build(), nil
+}
+
+func MustParseSet(str string) Set {
+	set, err := ParseSet(str)
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
+
+func (b *Builder) AddParsed(str string) error {
+	b.assertNotNil()
+	p := parser{input: str}
+	list, err := p.parseLiteral()
+	if err != nil {
+		return err
+	}
+	b.Add(list)
+	return nil
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) fail(offset int, format string, args ...any) error {
+	return p.failWith(offset, fmt.Errorf(format, args...))
+}
+
+func (p *parser) failWith(offset int, err error) error {
+	return &ParseError{Input: p.input, Offset: offset, Err: err}
+}
+
+func (p *parser) atEnd() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() (rune, int) {
+	if p.atEnd() {
+		return -1, 0
+	}
+	ch := rune(p.input[p.pos])
+	if ch < utf8.RuneSelf {
+		return ch, 1
+	}
+	return utf8.DecodeRuneInString(p.input[p.pos:])
+}
+
+func (p *parser) hasPrefix(prefix string) bool {
+	return len(p.input)-p.pos >= len(prefix) && p.input[p.pos:p.pos+len(prefix)] == prefix
+}
+
+// parseLiteral parses the notation produced by appendSource: "!.", ".", or
+// a bracketed list of runes and rune ranges.
+func (p *parser) parseLiteral() (PairList, error) {
+	var list PairList
+	switch {
+	case p.hasPrefix("!."):
+		p.pos += 2
+	case p.hasPrefix("."):
+		p.pos++
+		list = append(list, Pair{0, unicode.MaxRune})
+	case p.hasPrefix("["):
+		var err error
+		list, err = p.parseList()
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, p.fail(p.pos, "expected '[', '.', or '!.'")
+	}
+	if !p.atEnd() {
+		return nil, p.fail(p.pos, "unexpected trailing input")
+	}
+	return list, nil
+}
+
+func (p *parser) parseList() (PairList, error) {
+	var list PairList
+	p.pos++
+	for {
+		ch, size := p.peek()
+		switch {
+		case size == 0:
+			return nil, p.fail(p.pos, "unterminated set: missing ']'")
+		case ch == ']':
+			p.pos++
+			return list, nil
+		}
+
+		start := p.pos
+		lo, err := p.parseRune()
+		if err != nil {
+			return nil, err
+		}
+		hi := lo
+		if p.hasPrefix("-") {
+			p.pos++
+			hi, err = p.parseRune()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if lo > hi {
+			return nil, p.failWith(start, InvertedPairError{Pair{lo, hi}})
+		}
+		list = append(list, Pair{lo, hi})
+	}
+}
+
+// parseRune parses a single raw or escaped rune.
+func (p *parser) parseRune() (rune, error) {
+	ch, size := p.peek()
+	switch {
+	case size == 0:
+		return 0, p.fail(p.pos, "unexpected end of input")
+	case ch == utf8.RuneError && size == 1:
+		return 0, p.fail(p.pos, "invalid UTF-8")
+	case ch == '\\':
+		return p.parseEscape()
+	case ch == '[' || ch == ']' || ch == '-':
+		return 0, p.fail(p.pos, "unexpected %q; use \\%c to match it literally", ch, ch)
+	}
+	p.pos += size
+	return ch, nil
+}
+
+// parseEscape parses a backslash escape sequence.
+func (p *parser) parseEscape() (rune, error) {
+	start := p.pos
+	p.pos++
+	ch, size := p.peek()
+	if size == 0 {
+		return 0, p.fail(start, "incomplete escape sequence")
+	}
+	p.pos += size
+	switch ch {
+	case '0':
+		return 0, nil
+	case 't':
+		return '\t', nil
+	case 'n':
+		return '\n', nil
+	case 'v':
+		return '\v', nil
+	case 'f':
+		return '\f', nil
+	case 'r':
+		return '\r', nil
+	case 'z':
+		return unicode.MaxRune, nil
+	case 'x':
+		return p.parseHex(start, 2, 2)
+	case 'u':
+		if !p.hasPrefix("{") {
+			return p.parseHex(start, 4, 4)
+		}
+		p.pos++
+		value, err := p.parseHex(start, 1, 6)
+		if err != nil {
+			return 0, err
+		}
+		if !p.hasPrefix("}") {
+			return 0, p.fail(p.pos, "expected '}' to close \\u{...} escape")
+		}
+		p.pos++
+		return value, nil
+	}
+	if ch < utf8.RuneSelf && (unicode.IsPunct(ch) || unicode.IsSymbol(ch)) {
+		return ch, nil
+	}
+	return 0, p.fail(start, "unknown escape sequence \\%c", ch)
+}
+
+func (p *parser) parseHex(start int, minDigits int, maxDigits int) (rune, error) {
+	value := uint32(0)
+	n := 0
+	for n < maxDigits && !p.atEnd() {
+		digit, ok := hexValue(p.input[p.pos])
+		if !ok {
+			break
+		}
+		value = (value << 4) | digit
+		p.pos++
+		n++
+	}
+	if n < minDigits {
+		return 0, p.fail(p.pos, "expected at least %d hex digits in escape sequence", minDigits)
+	}
+	if value > unicode.MaxRune {
+		return 0, p.failWith(start, InvalidRuneError{rune(value)})
+	}
+	return rune(value), nil
+}
+
+func hexValue(ch byte) (uint32, bool) {
+	switch {
+	case ch >= '0' && ch <= '9':
+		return uint32(ch - '0'), true
+	case ch >= 'A' && ch <= 'F':
+		return uint32(ch-'A') + 10, true
+	case ch >= 'a' && ch <= 'f':
+		return uint32(ch-'a') + 10, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,85 @@
+package runeset
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSet(t *testing.T) {
+	type testRow struct {
+		Name   string
+		Input  string
+		Expect string
+		Offset int
+	}
+
+	testData := [...]testRow{
+		{Name: "Empty", Input: `!.`, Expect: `!.`},
+		{Name: "Full", Input: `.`, Expect: `.`},
+		{Name: "EmptyBrackets", Input: `[]`, Expect: `!.`},
+		{Name: "Single", Input: `[a]`, Expect: `[a]`},
+		{Name: "Range", Input: `[a-c]`, Expect: `[a-c]`},
+		{Name: "Multiple", Input: `[a-cg-i]`, Expect: `[a-cg-i]`},
+		{Name: "Unsorted", Input: `[g-ia-c]`, Expect: `[a-cg-i]`},
+		{Name: "Merge", Input: `[a-mn-z]`, Expect: `[a-z]`},
+		{Name: "Escapes", Input: `[\0\t\n\v\f\r]`, Expect: `[\0\t-\r]`},
+		{Name: "Hex", Input: `[\x20-\x2f\x7f]`, Expect: `[\x20-\x2f\x7f]`},
+		{Name: "Unicode4", Input: `[\u2000-\u200a]`, Expect: `[\u2000-\u200a]`},
+		{Name: "UnicodeBraced", Input: `[\u{1f600}-\u{1f64f}]`, Expect: `[\u{1f600}-\u{1f64f}]`},
+		{Name: "MaxRune", Input: `[a-\z]`, Expect: `[a-\z]`},
+		{Name: "FullRange", Input: `[\0-\z]`, Expect: `.`},
+		{Name: "Raw", Input: `[_αβγ]`, Expect: `[\x5fα-γ]`},
+		{Name: "Punct", Input: `[\-\]\[\\]`, Expect: `[\x2d\x5b-\x5d]`},
+
+		{Name: "Err-Blank", Input: ``, Offset: 0},
+		{Name: "Err-Garbage", Input: `abc`, Offset: 0},
+		{Name: "Err-Trailing", Input: `[a]b`, Offset: 3},
+		{Name: "Err-Unterminated", Input: `[a-c`, Offset: 4},
+		{Name: "Err-Inverted", Input: `[ab-a]`, Offset: 2},
+		{Name: "Err-BareDash", Input: `[-a]`, Offset: 1},
+		{Name: "Err-DanglingDash", Input: `[a-]`, Offset: 3},
+		{Name: "Err-ShortHex", Input: `[\x7]`, Offset: 4},
+		{Name: "Err-ShortUnicode", Input: `[\u12]`, Offset: 5},
+		{Name: "Err-TooBig", Input: `[\u{110000}]`, Offset: 1},
+		{Name: "Err-Unclosed", Input: `[\u{1f600]`, Offset: 9},
+		{Name: "Err-UnknownEscape", Input: `[\q]`, Offset: 1},
+		{Name: "Err-BadUTF8", Input: "[a\xff]", Offset: 2},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			set, err := ParseSet(row.Input)
+			if row.Expect == "" {
+				var perr *ParseError
+				if !errors.As(err, &perr) {
+					t.Fatalf("expected *ParseError, got %v (set %v)", err, set)
+				}
+				if perr.Offset != row.Offset {
+					t.Errorf("wrong offset: expect %d, actual %d (%v)", row.Offset, perr.Offset, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := set.String(); actual != row.Expect {
+				t.Errorf("wrong set:\n\texpect: %q\n\tactual: %q", row.Expect, actual)
+			}
+		})
+	}
+}
+
+func TestParseSet_RoundTrip(t *testing.T) {
+	for _, name := range ClassNames() {
+		t.Run(name, func(t *testing.T) {
+			expect := ForClass(name).String()
+			set, err := ParseSet(expect)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := set.String(); actual != expect {
+				t.Errorf("round trip failed:\n\texpect: %q\n\tactual: %q", expect, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package runeset
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// PersistentSet is an immutable set of runes stored as a treap of canonical
+// pairs.  Edits return a new PersistentSet in O(log n) expected time, which
+// shares every node the edit did not touch with the original, so that old
+// versions remain valid and cheap to keep.  The zero value is empty.
+type PersistentSet struct {
+	root *treapNode
+}
+
+type treapNode struct {
+	pair  Pair
+	prio  uint32
+	size  uint
+	left  *treapNode
+	right *treapNode
+}
+
+func NewPersistentSet(sources ...Source) PersistentSet {
+	set := Make(sources...)
+	var root *treapNode
+	set.EachPair(func(pair Pair) bool {
+		root = treapMerge(root, newTreapNode(pair, nil, nil))
+		return true
+	})
+	return PersistentSet{root}
+}
+
+// treapPriority derives a node's priority from its Lo, so that the shape of
+// a treap depends only on its contents.
+func treapPriority(lo rune) uint32 {
+	x := uint64(lo) + 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return uint32(x ^ (x >> 31))
+}
+
+func newTreapNode(pair Pair, left *treapNode, right *treapNode) *treapNode {
+	return treapWith(&treapNode{pair: pair, prio: treapPriority(pair.Lo)}, left, right)
+}
+
+// treapWith returns a copy of node with new children.
+func treapWith(node *treapNode, left *treapNode, right *treapNode) *treapNode {
+	out := *node
+	out.left = left
+	out.right = right
+	out.size = treapSize(left) + 1 + treapSize(right)
+	return &out
+}
+
+func treapSize(node *treapNode) uint {
+	if node == nil {
+		return 0
+	}
+	return node.size
+}
+
+// treapSplit splits node into the pairs with Lo < key and those with
+// Lo >= key.
+func treapSplit(node *treapNode, key rune) (*treapNode, *treapNode) {
+	if node == nil {
+		return nil, nil
+	}
+	if node.pair.Lo < key {
+		l, r := treapSplit(node.right, key)
+		return treapWith(node, node.left, l), r
+	}
+	l, r := treapSplit(node.left, key)
+	return l, treapWith(node, r, node.right)
+}
+
+// treapMerge joins a and b, where every pair of a precedes every pair of b.
+func treapMerge(a *treapNode, b *treapNode) *treapNode {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.prio >= b.prio:
+		return treapWith(a, a.left, treapMerge(a.right, b))
+	default:
+		return treapWith(b, treapMerge(a, b.left), b.right)
+	}
+}
+
+// treapPopMax removes the last pair of node.
+func treapPopMax(node *treapNode) (*treapNode, Pair) {
+	if node.right == nil {
+		return node.left, node.pair
+	}
+	rest, pair := treapPopMax(node.right)
+	return treapWith(node, node.left, rest), pair
+}
+
+func treapMax(node *treapNode) Pair {
+	for node.right != nil {
+		node = node.right
+	}
+	return node.pair
+}
+
+func (ps PersistentSet) AddRange(lo rune, hi rune) PersistentSet {
+	Pair{lo, hi}.AssertValid()
+
+	// left: pairs that start before lo; absorb the last if it touches
+	left, right := treapSplit(ps.root, lo)
+	if left != nil && treapMax(left).Hi+1 >= lo {
+		var last Pair
+		left, last = treapPopMax(left)
+		lo = last.Lo
+		hi = max(hi, last.Hi)
+	}
+
+	// middle: pairs that start within or just after [lo, hi]; absorb all
+	var middle *treapNode
+	if hi < unicode.MaxRune {
+		middle, right = treapSplit(right, hi+2)
+	} else {
+		middle, right = right, nil
+	}
+	if middle != nil {
+		hi = max(hi, treapMax(middle).Hi)
+	}
+
+	return PersistentSet{treapMerge(left, treapMerge(newTreapNode(Pair{lo, hi}, nil, nil), right))}
+}
+
+func (ps PersistentSet) RemoveRange(lo rune, hi rune) PersistentSet {
+	Pair{lo, hi}.AssertValid()
+
+	// left: pairs that start before lo; trim the last if it overlaps
+	left, right := treapSplit(ps.root, lo)
+	var keep []Pair
+	if left != nil && treapMax(left).Hi >= lo {
+		var last Pair
+		left, last = treapPopMax(left)
+		keep = append(keep, Pair{last.Lo, lo - 1})
+		if last.Hi > hi {
+			keep = append(keep, Pair{hi + 1, last.Hi})
+		}
+	}
+
+	// middle: pairs that start within [lo, hi]; keep only what sticks out
+	var middle *treapNode
+	if hi < unicode.MaxRune {
+		middle, right = treapSplit(right, hi+1)
+	} else {
+		middle, right = right, nil
+	}
+	if middle != nil {
+		if last := treapMax(middle); last.Hi > hi {
+			keep = append(keep, Pair{hi + 1, last.Hi})
+		}
+	}
+
+	for _, pair := range keep {
+		left = treapMerge(left, newTreapNode(pair, nil, nil))
+	}
+	return PersistentSet{treapMerge(left, right)}
+}
+
+func (ps PersistentSet) AddRune(ch rune) PersistentSet {
+	return ps.AddRange(ch, ch)
+}
+
+func (ps PersistentSet) RemoveRune(ch rune) PersistentSet {
+	return ps.RemoveRange(ch, ch)
+}
+
+// Add returns the union of the PersistentSet and src, in O(k log n) for a
+// Source of k pairs.
+func (ps PersistentSet) Add(src Source) PersistentSet {
+	n := src.Len()
+	for i := uint(0); i < n; i++ {
+		pair := src.At(i)
+		ps = ps.AddRange(pair.Lo, pair.Hi)
+	}
+	return ps
+}
+
+// Remove returns the difference of the PersistentSet and src, in
+// O(k log n) for a Source of k pairs.
+func (ps PersistentSet) Remove(src Source) PersistentSet {
+	n := src.Len()
+	for i := uint(0); i < n; i++ {
+		pair := src.At(i)
+		ps = ps.RemoveRange(pair.Lo, pair.Hi)
+	}
+	return ps
+}
+
+// Set returns the flat Set of runes in the PersistentSet.
+func (ps PersistentSet) Set() Set {
+	list := make([]Pair, 0, ps.Len())
+	var walk func(node *treapNode)
+	walk = func(node *treapNode) {
+		if node != nil {
+			walk(node.left)
+			list = append(list, node.pair)
+			walk(node.right)
+		}
+	}
+	walk(ps.root)
+	return makeSet(list)
+}
+
+func (ps PersistentSet) Len() uint {
+	return treapSize(ps.root)
+}
+
+func (ps PersistentSet) At(index uint) Pair {
+	node := ps.root
+	for node != nil {
+		n := treapSize(node.left)
+		switch {
+		case index < n:
+			node = node.left
+		case index > n:
+			index -= n + 1
+			node = node.right
+		default:
+			return node.pair
+		}
+	}
+	panic(fmt.Errorf("BUG: index %d out of range", index))
+}
+
+func (ps PersistentSet) Search(ch rune) (uint, bool) {
+	index := uint(0)
+	node := ps.root
+	for node != nil {
+		switch {
+		case ch < node.pair.Lo:
+			node = node.left
+		case ch > node.pair.Hi:
+			index += treapSize(node.left) + 1
+			node = node.right
+		default:
+			return index + treapSize(node.left), true
+		}
+	}
+	return index, false
+}
+
+func (ps PersistentSet) Contains(ch rune) bool {
+	_, found := ps.Search(ch)
+	return found
+}
+
+func (ps PersistentSet) IsEmpty() bool {
+	return ps.root == nil
+}
+
+func (ps PersistentSet) IsFull() bool {
+	return isFull(ps)
+}
+
+func (ps PersistentSet) Append(out []byte) []byte {
+	return appendSource(out, ps)
+}
+
+func (ps PersistentSet) String() string {
+	return toString(ps)
+}
+
+func (PersistentSet) canonical() {}
+
+var (
+	_ Source          = PersistentSet{}
+	_ alwaysCanonical = PersistentSet{}
+	_ Appender        = PersistentSet{}
+	_ fmt.Stringer    = PersistentSet{}
+)
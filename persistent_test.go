@@ -0,0 +1,66 @@
+package runeset
+
+import (
+	"math/rand"
+	"testing"
+	"unicode"
+)
+
+func TestPersistentSet(t *testing.T) {
+	rng := rand.New(rand.NewSource(12))
+	var history []PersistentSet
+	var expects []string
+	ps := NewPersistentSet(Pair{10, 20}, Pair{40, 50})
+	model := NewBuilder().AddRange(10, 20).AddRange(40, 50)
+	for iter := 0; iter < 3000; iter++ {
+		lo := rune(rng.Intn(modelDomain))
+		hi := lo + rune(rng.Intn(8))
+		if rng.Intn(16) == 0 {
+			hi = unicode.MaxRune
+		}
+		if rng.Intn(2) == 0 {
+			ps = ps.AddRange(lo, hi)
+			model.AddRange(lo, hi)
+		} else {
+			ps = ps.RemoveRange(lo, hi)
+			model.RemoveRange(lo, hi)
+		}
+		if actual, expect := ps.String(), model.String(); actual != expect {
+			t.Fatalf("after edit [%d, %d]:\n\texpect: %v\n\tactual: %v", lo, hi, expect, actual)
+		}
+		if !ps.Set().Equal(model.Build()) || !Equal(ps, model) {
+			t.Fatalf("%v: Set() is not equal to the model", ps)
+		}
+		for ch := rune(0); ch < modelDomain; ch++ {
+			i, found := ps.Search(ch)
+			j, expect := model.Search(ch)
+			if i != j || found != expect {
+				t.Fatalf("%v: Search(%d): expect %d, %v, got %d, %v", ps, ch, j, expect, i, found)
+			}
+		}
+		if iter%100 == 0 {
+			history = append(history, ps)
+			expects = append(expects, ps.String())
+		}
+	}
+	for i, old := range history {
+		if actual := old.String(); actual != expects[i] {
+			t.Errorf("version %d changed:\n\texpect: %v\n\tactual: %v", i, expects[i], actual)
+		}
+	}
+
+	big := NewPersistentSet(RuneList(func() []rune {
+		out := make([]rune, 20000)
+		for i := range out {
+			out[i] = rune(4 * i)
+		}
+		return out
+	}()))
+	edited := big.AddRune(2)
+	if big.Len() != 20000 || edited.Len() != 20001 || !edited.Contains(2) || big.Contains(2) {
+		t.Errorf("AddRune on a large set: got %d and %d pairs", big.Len(), edited.Len())
+	}
+	if edited.AddRune(1).Len() != 20000 || edited.RemoveRune(2).String() != big.String() {
+		t.Errorf("editing a large set gave the wrong result")
+	}
+}
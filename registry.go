@@ -0,0 +1,272 @@
+package runeset
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Registry maps class names to Sets.
+//
+// Names are matched loosely, as described by UAX #44 rule LM3: case,
+// whitespace, underscores and hyphens are ignored, so "White_Space",
+// "white space" and "WHITESPACE" all name the same class.  A name of the
+// form "property=value", such as "Script=Latn" or "gc=Lu", looks up value
+// among the values registered for that property.
+type Registry struct {
+	mu        sync.RWMutex
+	classes   map[string]*classEntry
+	aliases   map[string]aliasEntry
+	qualified map[string]map[string]string
+}
+
+type classEntry struct {
+	name  string
+	once  sync.Once
+	build func() Set
+	set   Set
+}
+
+func (entry *classEntry) get() Set {
+	entry.once.Do(func() {
+		if entry.build != nil {
+			// built-in classes are kept for the life of the process,
+			// so store them only once if several are equal
+			entry.set = gBuiltinInterner.Intern(entry.build())
+			entry.build = nil
+		}
+	})
+	return entry.set
+}
+
+type aliasEntry struct {
+	name   string
+	target string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		classes:   make(map[string]*classEntry, 64),
+		aliases:   make(map[string]aliasEntry, 32),
+		qualified: make(map[string]map[string]string, 4),
+	}
+}
+
+func DefaultRegistry() *Registry {
+	return gDefaultRegistry()
+}
+
+// Clone returns an independent copy of the Registry.  Classes registered
+// with the copy are not visible through the original, and vice versa.
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := &Registry{
+		classes:   make(map[string]*classEntry, len(r.classes)),
+		aliases:   make(map[string]aliasEntry, len(r.aliases)),
+		qualified: make(map[string]map[string]string, len(r.qualified)),
+	}
+	for key, entry := range r.classes {
+		out.classes[key] = entry
+	}
+	for key, alias := range r.aliases {
+		out.aliases[key] = alias
+	}
+	for prop, values := range r.qualified {
+		m := make(map[string]string, len(values))
+		for key, target := range values {
+			m[key] = target
+		}
+		out.qualified[prop] = m
+	}
+	return out
+}
+
+func (r *Registry) RegisterClass(name string, set Set) error {
+	return r.register(&classEntry{name: name, set: set})
+}
+
+// registerLazy registers a class whose Set is not built until the first
+// time it is looked up.
+func (r *Registry) registerLazy(name string, build func() Set) {
+	entry := &classEntry{name: name, build: build}
+	if err := r.register(entry); err != nil {
+		panic(fmt.Errorf("BUG: %w", err))
+	}
+}
+
+func (r *Registry) register(entry *classEntry) error {
+	if err := checkClassName(entry.name); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := looseName(entry.name)
+	if r.existsLocked(key) {
+		return DuplicateClassError{entry.name}
+	}
+	r.classes[key] = entry
+	return nil
+}
+
+func (r *Registry) RegisterAlias(alias string, target string) error {
+	if err := checkClassName(alias); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := looseName(alias)
+	if r.existsLocked(key) {
+		return DuplicateClassError{alias}
+	}
+	targetKey, found := r.resolveLocked(target)
+	if !found {
+		return UnknownClassError{target}
+	}
+	r.aliases[key] = aliasEntry{name: alias, target: targetKey}
+	return nil
+}
+
+func (r *Registry) MustRegisterClass(name string, set Set) {
+	if err := r.RegisterClass(name, set); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Registry) MustRegisterAlias(alias string, target string) {
+	if err := r.RegisterAlias(alias, target); err != nil {
+		panic(err)
+	}
+}
+
+// registerValue makes target reachable as "prop=value" for each of props.
+func (r *Registry) registerValue(props []string, value string, target string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	targetKey, found := r.resolveLocked(target)
+	if !found {
+		panic(fmt.Errorf("BUG: %w", UnknownClassError{target}))
+	}
+	for _, prop := range props {
+		propKey := looseName(prop)
+		values := r.qualified[propKey]
+		if values == nil {
+			values = make(map[string]string, 64)
+			r.qualified[propKey] = values
+		}
+		values[looseName(value)] = targetKey
+	}
+}
+
+func (r *Registry) LookupClass(name string) (Set, bool) {
+	r.mu.RLock()
+	entry := r.findLocked(name)
+	r.mu.RUnlock()
+
+	if entry == nil {
+		return Empty(), false
+	}
+	return entry.get(), true
+}
+
+func (r *Registry) ForClass(name string) Set {
+	if set, found := r.LookupClass(name); found {
+		return set
+	}
+	panic(UnknownClassError{name})
+}
+
+// Names returns the names of all registered classes and aliases, sorted.
+// Names are returned as they were registered, not in their loose form.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]string, 0, len(r.classes)+len(r.aliases))
+	for _, entry := range r.classes {
+		out = append(out, entry.name)
+	}
+	for _, alias := range r.aliases {
+		out = append(out, alias.name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (r *Registry) ParseExpression(str string) (Set, error) {
+	p := exprParser{parser: parser{input: str}, classes: r}
+	return p.parseExpression()
+}
+
+func (r *Registry) existsLocked(key string) bool {
+	if _, found := r.classes[key]; found {
+		return true
+	}
+	_, found := r.aliases[key]
+	return found
+}
+
+func (r *Registry) resolveLocked(name string) (string, bool) {
+	key := looseName(name)
+	if prop, value, ok := strings.Cut(key, "="); ok {
+		key, ok = r.qualified[prop][value]
+		if !ok {
+			return "", false
+		}
+	}
+	if alias, found := r.aliases[key]; found {
+		key = alias.target
+	}
+	_, found := r.classes[key]
+	return key, found
+}
+
+func (r *Registry) findLocked(name string) *classEntry {
+	key, found := r.resolveLocked(name)
+	if !found {
+		return nil
+	}
+	return r.classes[key]
+}
+
+func checkClassName(name string) error {
+	if looseName(name) == "" || strings.ContainsRune(name, '=') {
+		return fmt.Errorf("invalid character class name %q", name)
+	}
+	return nil
+}
+
+// looseName applies UAX #44 loose matching rule LM3 to name.
+func looseName(name string) string {
+	var sb strings.Builder
+	sb.Grow(len(name))
+	for _, ch := range name {
+		switch {
+		case ch == '_' || ch == '-' || unicode.IsSpace(ch):
+			// pass
+		default:
+			sb.WriteRune(unicode.ToLower(ch))
+		}
+	}
+	return sb.String()
+}
+
+func RegisterClass(name string, set Set) error {
+	return DefaultRegistry().RegisterClass(name, set)
+}
+
+func RegisterAlias(alias string, target string) error {
+	return DefaultRegistry().RegisterAlias(alias, target)
+}
+
+func ClassNames() []string {
+	return DefaultRegistry().Names()
+}
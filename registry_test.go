@@ -0,0 +1,184 @@
+package runeset
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"unicode"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if names := r.Names(); len(names) != 0 {
+		t.Fatalf("new registry is not empty: %q", names)
+	}
+
+	hashtag := Make(Pair{'0', '9'}, Pair{'A', 'Z'}, Pair{'_', '_'}, Pair{'a', 'z'})
+	if err := r.RegisterClass("hashtag.body", hashtag); err != nil {
+		t.Fatalf("RegisterClass: unexpected error: %v", err)
+	}
+	if err := r.RegisterAlias("tag", "hashtag.body"); err != nil {
+		t.Fatalf("RegisterAlias: unexpected error: %v", err)
+	}
+	if err := r.RegisterAlias("tag2", "tag"); err != nil {
+		t.Fatalf("RegisterAlias: unexpected error: %v", err)
+	}
+
+	if err := r.RegisterClass("tag", Empty()); !errors.Is(err, ErrDuplicateClass) {
+		t.Errorf("RegisterClass: expected ErrDuplicateClass, got %v", err)
+	}
+	if err := r.RegisterAlias("x", "no.such.class"); !errors.Is(err, ErrUnknownClass) {
+		t.Errorf("RegisterAlias: expected ErrUnknownClass, got %v", err)
+	}
+
+	for _, name := range []string{"hashtag.body", "tag", "tag2"} {
+		set, found := r.LookupClass(name)
+		if !found {
+			t.Errorf("LookupClass(%q): not found", name)
+			continue
+		}
+		if set.String() != hashtag.String() {
+			t.Errorf("LookupClass(%q): expect %v, got %v", name, hashtag, set)
+		}
+	}
+
+	if expect, actual := []string{"hashtag.body", "tag", "tag2"}, r.Names(); !reflect.DeepEqual(expect, actual) {
+		t.Errorf("Names: expect %q, got %q", expect, actual)
+	}
+
+	set, err := r.ParseExpression(`[\p{tag}--[:tag2:]]`)
+	if err != nil || !set.IsEmpty() {
+		t.Errorf("ParseExpression: got %v, %v", set, err)
+	}
+	if _, err := r.ParseExpression(`\p{L}`); !errors.Is(err, ErrUnknownClass) {
+		t.Errorf("ParseExpression: isolated registry should not know \"L\", got %v", err)
+	}
+}
+
+func TestRegistry_Clone(t *testing.T) {
+	r := DefaultRegistry().Clone()
+	if err := r.RegisterClass("test.clone", Make(Pair{'x', 'x'})); err != nil {
+		t.Fatalf("RegisterClass: unexpected error: %v", err)
+	}
+	if _, found := r.LookupClass("ascii.word"); !found {
+		t.Errorf("clone is missing \"ascii.word\"")
+	}
+	if _, found := LookupClass("test.clone"); found {
+		t.Errorf("class registered on clone leaked into the default registry")
+	}
+}
+
+func TestRegistry_Concurrent(t *testing.T) {
+	r := DefaultRegistry().Clone()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := "test.concurrent." + string(rune('a'+i))
+			if err := r.RegisterClass(name, Make(Rune('a'+i))); err != nil {
+				t.Errorf("RegisterClass(%q): unexpected error: %v", name, err)
+			}
+			for j := 0; j < 100; j++ {
+				r.ForClass("ascii.word")
+				r.LookupClass(name)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestRegistry_LooseMatching(t *testing.T) {
+	type testRow struct {
+		Name   string
+		Expect string
+	}
+
+	testData := [...]testRow{
+		{Name: "Uppercase_Letter", Expect: "Lu"},
+		{Name: "uppercase letter", Expect: "Lu"},
+		{Name: "UPPERCASE-LETTER", Expect: "Lu"},
+		{Name: "lu", Expect: "Lu"},
+		{Name: "Decimal_Number", Expect: "Nd"},
+		{Name: "gc=Lu", Expect: "Lu"},
+		{Name: "General_Category = Decimal_Number", Expect: "Nd"},
+		{Name: "gc=Letter", Expect: "L"},
+		{Name: "Greek", Expect: "Greek"},
+		{Name: "Grek", Expect: "Greek"},
+		{Name: "Script=Latn", Expect: "Latin"},
+		{Name: "sc=Latn", Expect: "Latin"},
+		{Name: "sc=latin", Expect: "Latin"},
+		{Name: "script=HAN", Expect: "Han"},
+		{Name: "whitespace", Expect: "White_Space"},
+		{Name: "White Space", Expect: "White_Space"},
+		{Name: "pattern-syntax", Expect: "Pattern_Syntax"},
+		{Name: "ASCII.Word", Expect: "ascii.word"},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			set, found := LookupClass(row.Name)
+			if !found {
+				t.Fatalf("LookupClass(%q): not found", row.Name)
+			}
+			if expect := ForClass(row.Expect); set.String() != expect.String() {
+				t.Errorf("LookupClass(%q): expect %v, got %v", row.Name, expect, set)
+			}
+		})
+	}
+
+	for _, name := range []string{"sc=Lu", "gc=Latn", "foo=bar", "=Lu", "Lu="} {
+		if _, found := LookupClass(name); found {
+			t.Errorf("LookupClass(%q): unexpectedly found", name)
+		}
+	}
+
+	r := DefaultRegistry().Clone()
+	if err := r.RegisterClass("WHITE-SPACE", Empty()); !errors.Is(err, ErrDuplicateClass) {
+		t.Errorf("RegisterClass: expected ErrDuplicateClass, got %v", err)
+	}
+	if err := r.RegisterClass("a=b", Empty()); err == nil {
+		t.Errorf("RegisterClass: expected error for name containing '='")
+	}
+}
+
+func TestDefaultRegistry_Lazy(t *testing.T) {
+	var calls atomic.Int32
+	lazy := NewRegistry()
+	lazy.registerLazy("counted", func() Set {
+		calls.Add(1)
+		return Make(Pair{'a', 'z'})
+	})
+	if n := calls.Load(); n != 0 {
+		t.Fatalf("expected the class to be unbuilt before first lookup, built %d times", n)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if actual, expect := lazy.ForClass("counted").String(), `[a-z]`; actual != expect {
+				t.Errorf("wrong set:\n\texpect: %q\n\tactual: %q", expect, actual)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected the class to be built once, built %d times", n)
+	}
+
+	r := newDefaultRegistry()
+	if actual, expect := r.ForClass("ascii.word").String(), `[0-9A-Z\x5fa-z]`; actual != expect {
+		t.Errorf("wrong set:\n\texpect: %q\n\tactual: %q", expect, actual)
+	}
+
+	for _, tables := range []map[string]*unicode.RangeTable{unicode.Categories, unicode.Scripts, unicode.Properties} {
+		for name, table := range tables {
+			if actual, expect := r.ForClass(name).String(), ForTable(table).String(); actual != expect {
+				t.Errorf("%s: lazily built class differs from ForTable:\n\texpect: %q\n\tactual: %q", name, expect, actual)
+			}
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package runeset
+
+import (
+	"cmp"
+	"slices"
+)
+
+// The relations in this file treat each Source as the set of runes it
+// covers, so Sources that differ only in how their runes are split into
+// pairs are equal.  Sources sorted by Lo are compared in a single sweep
+// without allocating; other Sources are first copied and sorted.
+
+func Equal[A Source, B Source](a A, b B) bool {
+	if x, ok := any(a).(Set); ok {
+		if y, ok := any(b).(Set); ok {
+			return x.Equal(y)
+		}
+	}
+	return Compare(a, b) == 0
+}
+
+// Compare orders Sources lexicographically by their canonical pairs, using
+// Pair.CompareTo.  If one is a prefix of the other, the shorter sorts first.
+func Compare[A Source, B Source](a A, b B) int {
+	if x, ok := any(a).(Set); ok {
+		if y, ok := any(b).(Set); ok {
+			return x.Compare(y)
+		}
+	}
+	ca := newRunCursor(a)
+	cb := newRunCursor(b)
+	for {
+		p, okP := ca.next()
+		q, okQ := cb.next()
+		switch {
+		case !okP && !okQ:
+			return 0
+		case !okP:
+			return -1
+		case !okQ:
+			return 1
+		}
+		if c := p.CompareTo(q); c != 0 {
+			return c
+		}
+	}
+}
+
+func IsSubsetOf[A Source, B Source](a A, b B) bool {
+	if x, ok := any(a).(Set); ok {
+		if y, ok := any(b).(Set); ok {
+			return x.IsSubsetOf(y)
+		}
+	}
+	ca := newRunCursor(a)
+	cb := newRunCursor(b)
+	q, okQ := cb.next()
+	for {
+		p, okP := ca.next()
+		if !okP {
+			return true
+		}
+		for okQ && q.Hi < p.Lo {
+			q, okQ = cb.next()
+		}
+		if !okQ || q.Lo > p.Lo || q.Hi < p.Hi {
+			return false
+		}
+	}
+}
+
+func IsSupersetOf[A Source, B Source](a A, b B) bool {
+	return IsSubsetOf(b, a)
+}
+
+func Overlaps[A Source, B Source](a A, b B) bool {
+	if x, ok := any(a).(Set); ok {
+		if y, ok := any(b).(Set); ok {
+			return x.Overlaps(y)
+		}
+	}
+	ca := newRunCursor(a)
+	cb := newRunCursor(b)
+	p, okP := ca.next()
+	q, okQ := cb.next()
+	for okP && okQ {
+		if max(p.Lo, q.Lo) <= min(p.Hi, q.Hi) {
+			return true
+		}
+		if p.Hi < q.Hi {
+			p, okP = ca.next()
+		} else {
+			q, okQ = cb.next()
+		}
+	}
+	return false
+}
+
+func IsDisjoint[A Source, B Source](a A, b B) bool {
+	return !Overlaps(a, b)
+}
+
+func (set Set) Equal(other Set) bool {
+	if a, ok := set.plainList(); ok {
+		if b, ok := other.plainList(); ok {
+			return slices.Equal(a, b)
+		}
+	}
+	return set.Compare(other) == 0
+}
+
+func (set Set) Compare(other Set) int {
+	if a, ok := set.plainList(); ok {
+		if b, ok := other.plainList(); ok {
+			return slices.CompareFunc(a, b, Pair.CompareTo)
+		}
+	}
+	n, m := set.Len(), other.Len()
+	for i := uint(0); i < n && i < m; i++ {
+		if c := set.At(i).CompareTo(other.At(i)); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(n, m)
+}
+
+func (set Set) IsSubsetOf(other Set) bool {
+	n, m := set.Len(), other.Len()
+	j := uint(0)
+	for i := uint(0); i < n; i++ {
+		p := set.At(i)
+		for j < m && other.At(j).Hi < p.Lo {
+			j++
+		}
+		if j >= m {
+			return false
+		}
+		if q := other.At(j); q.Lo > p.Lo || q.Hi < p.Hi {
+			return false
+		}
+	}
+	return true
+}
+
+func (set Set) IsSupersetOf(other Set) bool {
+	return other.IsSubsetOf(set)
+}
+
+func (set Set) Overlaps(other Set) bool {
+	n, m := set.Len(), other.Len()
+	i, j := uint(0), uint(0)
+	for i < n && j < m {
+		p, q := set.At(i), other.At(j)
+		if max(p.Lo, q.Lo) <= min(p.Hi, q.Hi) {
+			return true
+		}
+		if p.Hi < q.Hi {
+			i++
+		} else {
+			j++
+		}
+	}
+	return false
+}
+
+func (set Set) IsDisjoint(other Set) bool {
+	return !set.Overlaps(other)
+}
+
+// runCursor yields the maximal runs of a Source sorted by Lo, merging pairs
+// that overlap or touch.  If the Source is not sorted, a sorted copy is
+// made.
+type runCursor[S Source] struct {
+	src  S
+	list PairList
+	n    uint
+	i    uint
+}
+
+func newRunCursor[S Source](src S) runCursor[S] {
+	if isSorted(src) {
+		return runCursor[S]{src: src, n: src.Len()}
+	}
+	n := src.Len()
+	list := make([]Pair, 0, n)
+	for i := uint(0); i < n; i++ {
+		list = append(list, sourceAt(src, i))
+	}
+	list = normalizeList(list)
+	return runCursor[S]{list: list, n: uint(len(list))}
+}
+
+func (c *runCursor[S]) at(index uint) Pair {
+	if c.list != nil {
+		return c.list[index]
+	}
+	return c.src.At(index)
+}
+
+func (c *runCursor[S]) next() (Pair, bool) {
+	if c.i >= c.n {
+		return Pair{}, false
+	}
+	pair := c.at(c.i)
+	c.i++
+	for c.i < c.n {
+		q := c.at(c.i)
+		if pair.Hi+1 < q.Lo {
+			break
+		}
+		pair.Hi = max(pair.Hi, q.Hi)
+		c.i++
+	}
+	return pair, true
+}
+
+// isSorted reports whether src lists valid pairs in non-decreasing order of
+// Lo.  Unlike isCanonical, it permits overlapping and adjacent pairs.
+func isSorted[S Source](src S) bool {
+	if knownCanonical(src) {
+		return true
+	}
+	n := src.Len()
+	var prev Pair
+	for i := uint(0); i < n; i++ {
+		pair := sourceAt(src, i)
+		if !pair.IsValid() {
+			return false
+		}
+		if i > 0 && pair.Lo < prev.Lo {
+			return false
+		}
+		prev = pair
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package runeset
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRelations(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for iter := 0; iter < 2000; iter++ {
+		x := randomPairs(rng)
+		y := randomPairs(rng)
+		if rng.Intn(4) == 0 {
+			y = append(PairList(nil), x...)
+			rng.Shuffle(len(y), func(i, j int) { y[i], y[j] = y[j], y[i] })
+		}
+		mx, my := modelOf(x), modelOf(y)
+
+		subset, overlaps := true, false
+		for ch := range mx {
+			if my[ch] {
+				overlaps = true
+			} else {
+				subset = false
+			}
+		}
+		equal := subset && len(mx) == len(my)
+		sx, sy := Make(x), Make(y)
+
+		for _, pair := range [][2]Source{{x, y}, {sx, sy}, {x, sy}} {
+			a, b := pair[0], pair[1]
+			if actual := Equal(a, b); actual != equal {
+				t.Fatalf("Equal(%v, %v): expect %v", a, b, equal)
+			}
+			if actual := Compare(a, b) == 0; actual != equal {
+				t.Fatalf("Compare(%v, %v) == 0: expect %v", a, b, equal)
+			}
+			if actual := IsSubsetOf(a, b); actual != subset {
+				t.Fatalf("IsSubsetOf(%v, %v): expect %v", a, b, subset)
+			}
+			if actual := IsSupersetOf(b, a); actual != subset {
+				t.Fatalf("IsSupersetOf(%v, %v): expect %v", b, a, subset)
+			}
+			if actual := Overlaps(a, b); actual != overlaps {
+				t.Fatalf("Overlaps(%v, %v): expect %v", a, b, overlaps)
+			}
+			if actual := IsDisjoint(a, b); actual == overlaps {
+				t.Fatalf("IsDisjoint(%v, %v): expect %v", a, b, !overlaps)
+			}
+		}
+		if c1, c2 := Compare(sx, sy), Compare(sy, sx); c1 != -c2 {
+			t.Fatalf("Compare(%v, %v) = %d is not antisymmetric: %d", sx, sy, c1, c2)
+		}
+	}
+
+	a := PairList{{'a', 'c'}, {'d', 'f'}, {'x', 'z'}}
+	b := Make(Pair{'a', 'f'}, Pair{'x', 'z'})
+	allocs := testing.AllocsPerRun(100, func() {
+		if !Equal(a, b) || !IsSubsetOf(a, b) || !Overlaps(a, b) {
+			t.Fatal("wrong result")
+		}
+		if !b.Equal(b) || !b.IsSubsetOf(b) || Compare(b, b) != 0 {
+			t.Fatal("wrong result")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expected relations on sorted Sources not to allocate, got %v allocations", allocs)
+	}
+}
@@ -11,11 +11,17 @@ func (r Rune) IsValid() bool {
 	return isValidRune(rune(r))
 }
 
-func (r Rune) AssertValid() {
+func (r Rune) Validate() error {
 	if r.IsValid() {
-		return
+		return nil
+	}
+	return InvalidRuneError{rune(r)}
+}
+
+func (r Rune) AssertValid() {
+	if err := r.Validate(); err != nil {
+		panic(fmt.Errorf("BUG: %w", err))
 	}
-	panic(fmt.Errorf("BUG: U+%04X is not a valid Unicode code point", uint32(r)))
 }
 
 func (r Rune) Len() uint {
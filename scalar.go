@@ -0,0 +1,85 @@
+package runeset
+
+import (
+	"sync"
+	"unicode"
+)
+
+// The functions in this file return the seven basic types of code point
+// defined by the Unicode Standard, section 2.4.  Together they partition
+// Full().  Each is built on first use.
+
+// Graphic returns the code points of general category L, M, N, P, S or Zs.
+func Graphic() Set {
+	return gGraphic()
+}
+
+// Format returns the code points of general category Cf, Zl or Zp.
+func Format() Set {
+	return gFormat()
+}
+
+// Control returns the code points of general category Cc.
+func Control() Set {
+	return gControl()
+}
+
+// PrivateUse returns the code points of general category Co.
+func PrivateUse() Set {
+	return gPrivateUse()
+}
+
+// Surrogates returns U+D800 .. U+DFFF, which are not scalar values and
+// cannot be encoded in UTF-8.
+func Surrogates() Set {
+	return gSurrogates()
+}
+
+// Noncharacters returns U+FDD0 .. U+FDEF, plus the last two code points of
+// every plane.
+func Noncharacters() Set {
+	return gNoncharacters()
+}
+
+// Reserved returns the unassigned code points which are not noncharacters.
+func Reserved() Set {
+	return gReserved()
+}
+
+// ScalarValues returns every code point except the surrogates.  It is the
+// universe of runes that can appear in valid UTF-8.
+func ScalarValues() Set {
+	return gScalarValues()
+}
+
+var (
+	gGraphic = sync.OnceValue(func() Set {
+		return Make(ForTable(unicode.L), ForTable(unicode.M), ForTable(unicode.N), ForTable(unicode.P), ForTable(unicode.S), ForTable(unicode.Zs))
+	})
+	gFormat = sync.OnceValue(func() Set {
+		return Make(ForTable(unicode.Cf), ForTable(unicode.Zl), ForTable(unicode.Zp))
+	})
+	gControl = sync.OnceValue(func() Set {
+		return ForTable(unicode.Cc)
+	})
+	gPrivateUse = sync.OnceValue(func() Set {
+		return ForTable(unicode.Co)
+	})
+	gSurrogates = sync.OnceValue(func() Set {
+		return Make(Pair{0xd800, 0xdfff})
+	})
+	gNoncharacters = sync.OnceValue(func() Set {
+		var b Builder
+		b.Reset().AddRange(0xfdd0, 0xfdef)
+		for plane := rune(0); plane <= unicode.MaxRune; plane += 0x10000 {
+			b.AddRange(plane|0xfffe, plane|0xffff)
+		}
+		return b.Build()
+	})
+	gReserved = sync.OnceValue(func() Set {
+		return Union(Graphic(), Format(), Control(), PrivateUse(), Surrogates(), Noncharacters()).Complement()
+	})
+	gScalarValues = sync.OnceValue(func() Set {
+		return Surrogates().Complement()
+	})
+)
@@ -0,0 +1,62 @@
+package runeset
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestCodePointTypes(t *testing.T) {
+	types := map[string]Set{
+		"Graphic":       Graphic(),
+		"Format":        Format(),
+		"Control":       Control(),
+		"PrivateUse":    PrivateUse(),
+		"Surrogates":    Surrogates(),
+		"Noncharacters": Noncharacters(),
+		"Reserved":      Reserved(),
+	}
+	var total uint
+	for name, set := range types {
+		if set.IsEmpty() {
+			t.Errorf("%s: unexpectedly empty", name)
+		}
+		total += set.Count()
+		for other, set2 := range types {
+			if name < other && set.Overlaps(set2) {
+				t.Errorf("%s overlaps %s", name, other)
+			}
+		}
+	}
+	if total != unicode.MaxRune+1 {
+		t.Errorf("code point types cover %d code points, expected %d", total, unicode.MaxRune+1)
+	}
+
+	if actual, expect := Noncharacters().Count(), uint(66); actual != expect {
+		t.Errorf("Noncharacters: expect %d, got %d", expect, actual)
+	}
+	for _, ch := range []rune{'A', ' ', 0xad, '\n', 0xe000, 0xd800, 0xfffe, 0x378} {
+		n := 0
+		for _, set := range types {
+			if set.Contains(ch) {
+				n++
+			}
+		}
+		if n != 1 {
+			t.Errorf("%U: contained in %d types", ch, n)
+		}
+	}
+
+	negated := NewBuilder().AddRange('a', 'z').NegateWithin(ScalarValues()).Build()
+	if negated.Overlaps(Surrogates()) || negated.Contains('m') || !negated.Contains('A') {
+		t.Errorf("NegateWithin(ScalarValues()): wrong result %v", negated)
+	}
+	if actual, expect := negated.Count(), ScalarValues().Count()-26; actual != expect {
+		t.Errorf("NegateWithin(ScalarValues()): expect %d runes, got %d", expect, actual)
+	}
+
+	// a universe which is not canonical is normalized first
+	unsorted := PairList{{'x', 'z'}, {'a', 'c'}, {'b', 'f'}}
+	if actual, expect := NewBuilder().AddRune('b').AddRune('y').NegateWithin(unsorted).String(), `[ac-fxz]`; actual != expect {
+		t.Errorf("NegateWithin(%v): expect %s, got %s", unsorted, expect, actual)
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"unicode"
 )
 
-type Set struct{ list []Pair }
+type Set struct {
+	list []Pair
+	info *setInfo
+}
 
 func Make(sources ...Source) Set {
 	var b Builder
@@ -16,18 +19,27 @@ func Empty() Set {
 	return Set{}
 }
 
-var gFull = [1]Pair{Pair{Lo: 0, Hi: unicode.MaxRune}}
+var (
+	gFull     = [1]Pair{Pair{Lo: 0, Hi: unicode.MaxRune}}
+	gFullInfo = setInfo{latin1: [4]uint64{^uint64(0), ^uint64(0), ^uint64(0), ^uint64(0)}}
+)
 
 func Full() Set {
 	list := gFull[:]
-	return Set{list: list}
+	return Set{list: list, info: &gFullInfo}
 }
 
 func (set Set) Len() uint {
+	if p := set.packed(); p != nil {
+		return p.len()
+	}
 	return uint(len(set.list))
 }
 
 func (set Set) At(index uint) Pair {
+	if p := set.packed(); p != nil {
+		return p.at(index)
+	}
 	return set.list[index]
 }
 
@@ -36,6 +48,9 @@ func (set Set) Search(ch rune) (uint, bool) {
 }
 
 func (set Set) Contains(ch rune) bool {
+	if uint32(ch) < 0x100 {
+		return set.info != nil && (set.info.latin1[ch>>6]&(1<<(ch&63))) != 0
+	}
 	_, found := set.Search(ch)
 	return found
 }
@@ -60,8 +75,43 @@ func (set Set) Builder() *Builder {
 	return NewBuilder().Add(set)
 }
 
+func (set Set) EachPair(fn func(Pair) bool) {
+	EachPair(set, fn)
+}
+
+func (set Set) EachPairReverse(fn func(Pair) bool) {
+	EachPairReverse(set, fn)
+}
+
+func (set Set) EachPairWithin(lo rune, hi rune, fn func(Pair) bool) {
+	EachPairWithin(set, lo, hi, fn)
+}
+
+func (set Set) EachRune(fn func(rune) bool) {
+	EachRune(set, fn)
+}
+
+func (set Set) EachRuneReverse(fn func(rune) bool) {
+	EachRuneReverse(set, fn)
+}
+
+func (set Set) EachRuneWithin(lo rune, hi rune, fn func(rune) bool) {
+	EachRuneWithin(set, lo, hi, fn)
+}
+
+func (set Set) All() func(yield func(Pair) bool) {
+	return set.EachPair
+}
+
+func (set Set) Runes() func(yield func(rune) bool) {
+	return set.EachRune
+}
+
+func (Set) canonical() {}
+
 var (
-	_ Source       = Set{}
-	_ Appender     = Set{}
-	_ fmt.Stringer = Set{}
+	_ Source          = Set{}
+	_ alwaysCanonical = Set{}
+	_ Appender        = Set{}
+	_ fmt.Stringer    = Set{}
 )
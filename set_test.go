@@ -1,6 +1,7 @@
 package runeset
 
 import (
+	"math/rand"
 	"testing"
 	"unicode"
 )
@@ -391,3 +392,35 @@ func TestSet(t *testing.T) {
 		})
 	}
 }
+
+func TestSet_ContainsLatin1(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for iter := 0; iter < 500; iter++ {
+		var b Builder
+		b.Reset()
+		for i := rng.Intn(8); i > 0; i-- {
+			lo := rune(rng.Intn(0x120))
+			b.AddRange(lo, lo+rune(rng.Intn(40)))
+		}
+		set := b.Build()
+		lo := rune(rng.Intn(0x100))
+		for _, s := range []Set{set, set.Clip(lo, lo+rune(rng.Intn(0x40))).Set(), set.Slice(0, set.Len()/2), set.Complement()} {
+			for ch := rune(-1); ch < 0x140; ch++ {
+				_, expect := s.Search(ch)
+				if actual := s.Contains(ch); actual != expect {
+					t.Fatalf("%v: Contains(%U): expect %v, got %v", s, ch, expect, actual)
+				}
+			}
+		}
+	}
+
+	word := ForClass("word")
+	allocs := testing.AllocsPerRun(100, func() {
+		if !word.Contains('x') || word.Contains('-') {
+			t.Fatal("wrong result")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Contains: expected no allocations, got %v", allocs)
+	}
+}
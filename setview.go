@@ -0,0 +1,133 @@
+package runeset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+)
+
+// SetView is a read-only Source backed directly by a byte buffer, such as
+// one embedded with go:embed, without decoding it.
+//
+// The layout of the buffer is a sequence of 8-byte records, one per pair,
+// in order: each record is Lo followed by Hi, both as 4-byte little-endian
+// unsigned integers.  The pairs must be canonical: valid, sorted, and
+// neither overlapping nor adjacent.  An empty buffer is the empty set.
+type SetView struct {
+	buf []byte
+}
+
+const setViewRecordLen = 8
+
+// NewSetView validates buf and returns a SetView over it.  The SetView
+// refers to buf, which must not be modified afterward.
+func NewSetView(buf []byte) (SetView, error) {
+	if err := ValidateSetView(buf); err != nil {
+		return SetView{}, err
+	}
+	return SetView{buf}, nil
+}
+
+func MustNewSetView(buf []byte) SetView {
+	view, err := NewSetView(buf)
+	if err != nil {
+		panic(err)
+	}
+	return view
+}
+
+// ValidateSetView checks that buf is in the layout of a SetView.  The error,
+// if any, is a MalformedError.
+func ValidateSetView(buf []byte) error {
+	if rem := len(buf) % setViewRecordLen; rem != 0 {
+		return MalformedError{Offset: len(buf) - rem, Reason: "truncated pair"}
+	}
+	var prev Pair
+	for offset := 0; offset < len(buf); offset += setViewRecordLen {
+		lo := binary.LittleEndian.Uint32(buf[offset:])
+		hi := binary.LittleEndian.Uint32(buf[offset+4:])
+		pair := Pair{rune(lo), rune(hi)}
+		if err := pair.Validate(); err != nil {
+			return MalformedError{Offset: offset, Reason: "invalid pair", Err: err}
+		}
+		if offset > 0 && prev.Hi+1 >= pair.Lo {
+			return MalformedError{Offset: offset, Reason: "pairs are not sorted, or overlap or touch"}
+		}
+		prev = pair
+	}
+	return nil
+}
+
+// AppendSetView appends the pairs of src to out in the layout of a SetView.
+// Sources which are not canonical are normalized first.
+func AppendSetView(out []byte, src Source) []byte {
+	src = canonicalSource(src)
+	n := src.Len()
+	out = slices.Grow(out, int(n)*setViewRecordLen)
+	for i := uint(0); i < n; i++ {
+		pair := src.At(i)
+		out = binary.LittleEndian.AppendUint32(out, uint32(pair.Lo))
+		out = binary.LittleEndian.AppendUint32(out, uint32(pair.Hi))
+	}
+	return out
+}
+
+// Bytes returns the buffer behind the SetView.
+func (view SetView) Bytes() []byte {
+	return view.buf
+}
+
+// Set decodes the SetView into a Set.
+func (view SetView) Set() Set {
+	n := view.Len()
+	list := make([]Pair, n)
+	for i := range list {
+		list[i] = view.At(uint(i))
+	}
+	return makeSet(list)
+}
+
+func (view SetView) Len() uint {
+	return uint(len(view.buf) / setViewRecordLen)
+}
+
+func (view SetView) At(index uint) Pair {
+	record := view.buf[index*setViewRecordLen : (index+1)*setViewRecordLen]
+	lo := binary.LittleEndian.Uint32(record)
+	hi := binary.LittleEndian.Uint32(record[4:])
+	return Pair{rune(lo), rune(hi)}
+}
+
+func (view SetView) Search(ch rune) (uint, bool) {
+	return searchSource(view, ch)
+}
+
+func (view SetView) Contains(ch rune) bool {
+	_, found := view.Search(ch)
+	return found
+}
+
+func (view SetView) IsEmpty() bool {
+	return isEmpty(view)
+}
+
+func (view SetView) IsFull() bool {
+	return isFull(view)
+}
+
+func (view SetView) Append(out []byte) []byte {
+	return appendSource(out, view)
+}
+
+func (view SetView) String() string {
+	return toString(view)
+}
+
+func (SetView) canonical() {}
+
+var (
+	_ Source          = SetView{}
+	_ alwaysCanonical = SetView{}
+	_ Appender        = SetView{}
+	_ fmt.Stringer    = SetView{}
+)
@@ -0,0 +1,58 @@
+package runeset
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetView(t *testing.T) {
+	for _, name := range []string{"L", "Nd", "ascii.word", "Han"} {
+		set := ForClass(name)
+		buf := AppendSetView(nil, set)
+		view, err := NewSetView(buf)
+		if err != nil {
+			t.Fatalf("%s: NewSetView: %v", name, err)
+		}
+		if !Equal(view, set) || view.String() != set.String() || !view.Set().Equal(set) {
+			t.Errorf("%s: SetView differs from the Set", name)
+		}
+		for _, ch := range []rune{'a', '5', '_', 0x4e00, 0x10ffff} {
+			if view.Contains(ch) != set.Contains(ch) {
+				t.Errorf("%s: Contains(%U): expect %v", name, ch, set.Contains(ch))
+			}
+		}
+	}
+
+	if actual, expect := AppendSetView([]byte{0xff}, RuneList{'b', 'a'}), []byte{0xff, 'a', 0, 0, 0, 'b', 0, 0, 0}; string(actual) != string(expect) {
+		t.Errorf("AppendSetView: expect % x, got % x", expect, actual)
+	}
+
+	type testRow struct {
+		Name   string
+		Input  []byte
+		Offset int
+		Err    error
+	}
+	testData := [...]testRow{
+		{"truncated", []byte{1, 0, 0, 0, 2, 0, 0}, 0, nil},
+		{"inverted", []byte{2, 0, 0, 0, 1, 0, 0, 0}, 0, ErrInvertedPair},
+		{"invalid", []byte{0, 0, 0, 0, 0, 0, 0x11, 0}, 0, ErrInvalidRune},
+		{"touching", []byte{1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0, 3, 0, 0, 0}, 8, nil},
+		{"unsorted", []byte{5, 0, 0, 0, 6, 0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0}, 8, nil},
+	}
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			_, err := NewSetView(row.Input)
+			var merr MalformedError
+			if !errors.As(err, &merr) || !errors.Is(err, ErrMalformed) {
+				t.Fatalf("expected a MalformedError, got %v", err)
+			}
+			if merr.Offset != row.Offset {
+				t.Errorf("expected offset %d, got %d", row.Offset, merr.Offset)
+			}
+			if row.Err != nil && !errors.Is(err, row.Err) {
+				t.Errorf("expected %v, got %v", row.Err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,432 @@
+package runeset
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"unicode"
+)
+
+// StridedRange is the runes Lo, Lo+Stride, Lo+2*Stride, ... up to Hi, like
+// unicode.Range16 and unicode.Range32.
+type StridedRange struct {
+	Lo     rune
+	Hi     rune
+	Stride uint32
+}
+
+func (r StridedRange) contains(ch rune) bool {
+	return ch >= r.Lo && ch <= r.Hi && uint32(ch-r.Lo)%r.Stride == 0
+}
+
+// last returns the greatest member of the range, which is Hi only if Hi-Lo
+// is a multiple of Stride.
+func (r StridedRange) last() rune {
+	return r.Hi - rune(uint32(r.Hi-r.Lo)%r.Stride)
+}
+
+// count returns the number of members of the range.
+func (r StridedRange) count() uint32 {
+	return uint32(r.Hi-r.Lo)/r.Stride + 1
+}
+
+// numPairs returns the number of runs of consecutive members in the range:
+// one if the stride is 1, or else one per member.
+func (r StridedRange) numPairs() uint32 {
+	if r.Stride == 1 {
+		return 1
+	}
+	return r.count()
+}
+
+// StridedSet is a set of runes stored as sorted, non-overlapping strided
+// ranges, as in a unicode.RangeTable.  Classes such as unicode.Upper, whose
+// members alternate with non-members, take a few ranges instead of one Pair
+// per member.
+//
+// Nothing is ever expanded: At and Search find the range holding a pair by
+// binary search over a table of the index of each range's first pair, and
+// the set algebra sweeps the ranges of both operands at once.
+type StridedSet struct {
+	ranges []StridedRange
+
+	// starts[k] is the index of the pair holding the first member of
+	// ranges[k], and starts[len(ranges)] is the number of pairs.  If the
+	// last member of a range is adjacent to the first member of the next,
+	// both are in the same pair.
+	starts []uint32
+}
+
+// newStridedSet returns a StridedSet of ranges, which must be sorted and
+// non-overlapping, with each Hi a member and the stride of each single
+// member range 1.
+func newStridedSet(ranges []StridedRange) *StridedSet {
+	starts := make([]uint32, len(ranges)+1)
+	for k, r := range ranges {
+		starts[k+1] = starts[k] + r.numPairs()
+		if k+1 < len(ranges) && r.Hi+1 == ranges[k+1].Lo {
+			starts[k+1]--
+		}
+	}
+	return &StridedSet{ranges: ranges, starts: starts}
+}
+
+// NewStridedSet returns the union of sources, with each run of equally
+// spaced single runes folded into one strided range.
+func NewStridedSet(sources ...Source) *StridedSet {
+	var ranges []StridedRange
+	Make(sources...).EachPair(func(pair Pair) bool {
+		ranges = appendStrided(ranges, pair)
+		return true
+	})
+	return newStridedSet(ranges)
+}
+
+// appendStrided appends pair to ranges, extending the last range if pair is
+// a single rune one stride beyond it.  Pairs must be appended in canonical
+// order.
+func appendStrided(ranges []StridedRange, pair Pair) []StridedRange {
+	n := len(ranges)
+	if pair.Lo != pair.Hi || n <= 0 {
+		return append(ranges, StridedRange{pair.Lo, pair.Hi, 1})
+	}
+	last := &ranges[n-1]
+	switch {
+	case last.Lo == last.Hi:
+		last.Hi = pair.Lo
+		last.Stride = uint32(pair.Lo - last.Lo)
+	case last.Stride > 1 && pair.Lo-last.Hi == rune(last.Stride):
+		last.Hi = pair.Lo
+	default:
+		ranges = append(ranges, StridedRange{pair.Lo, pair.Hi, 1})
+	}
+	return ranges
+}
+
+// ForTableStrided is like ForTable, but keeps the strided ranges of the
+// table as they are.  Tables that are not sorted and non-overlapping are
+// normalized through NewStridedSet.
+func ForTableStrided(table *unicode.RangeTable) *StridedSet {
+	if table == nil {
+		return newStridedSet(nil)
+	}
+	ranges := make([]StridedRange, 0, len(table.R16)+len(table.R32))
+	add := func(lo uint32, hi uint32, stride uint32) bool {
+		if lo > hi || hi > unicode.MaxRune || stride == 0 {
+			return false
+		}
+		r := StridedRange{rune(lo), rune(hi), stride}
+		r.Hi = r.last()
+		if r.Lo == r.Hi {
+			r.Stride = 1
+		}
+		if n := len(ranges); n > 0 && ranges[n-1].Hi >= r.Lo {
+			return false
+		}
+		ranges = append(ranges, r)
+		return true
+	}
+	for _, r := range table.R16 {
+		if !add(uint32(r.Lo), uint32(r.Hi), uint32(r.Stride)) {
+			return NewStridedSet(ForTable(table))
+		}
+	}
+	for _, r := range table.R32 {
+		if !add(r.Lo, r.Hi, r.Stride) {
+			return NewStridedSet(ForTable(table))
+		}
+	}
+	return newStridedSet(ranges)
+}
+
+// Ranges returns the strided ranges of the StridedSet.  The caller must not
+// modify them.
+func (s *StridedSet) Ranges() []StridedRange {
+	return s.ranges
+}
+
+// RangeTable converts the StridedSet to a unicode.RangeTable.  A range
+// which straddles U+10000 is split between R16 and R32.
+func (s *StridedSet) RangeTable() *unicode.RangeTable {
+	table := &unicode.RangeTable{}
+	for _, r := range s.ranges {
+		stride := r.Stride
+		if r.Lo == r.Hi {
+			stride = 1
+		}
+		if r.Lo <= 0xffff {
+			hi := r.Hi
+			if hi > 0xffff {
+				hi = StridedRange{r.Lo, 0xffff, stride}.last()
+			}
+			r16Stride := stride
+			if r.Lo == hi {
+				r16Stride = 1
+			}
+			table.R16 = append(table.R16, unicode.Range16{Lo: uint16(r.Lo), Hi: uint16(hi), Stride: uint16(r16Stride)})
+			if hi <= unicode.MaxLatin1 {
+				table.LatinOffset++
+			}
+			if hi == r.Hi {
+				continue
+			}
+			r.Lo = hi + rune(stride)
+			if r.Lo == r.Hi {
+				stride = 1
+			}
+		}
+		table.R32 = append(table.R32, unicode.Range32{Lo: uint32(r.Lo), Hi: uint32(r.Hi), Stride: stride})
+	}
+	return table
+}
+
+// stridedCursor visits the canonical pairs of a StridedSet in order.
+type stridedCursor struct {
+	ranges []StridedRange
+	k      int
+	off    uint32
+}
+
+// run returns the next run of consecutive members of the current range, and
+// moves on to the next range after its last member.
+func (c *stridedCursor) run() (Pair, bool) {
+	if c.k >= len(c.ranges) {
+		return Pair{}, false
+	}
+	r := c.ranges[c.k]
+	if r.Stride == 1 {
+		c.k++
+		return Pair{r.Lo, r.Hi}, true
+	}
+	ch := r.Lo + rune(c.off*r.Stride)
+	c.off++
+	if ch == r.Hi {
+		c.k, c.off = c.k+1, 0
+	}
+	return Pair{ch, ch}, true
+}
+
+func (c *stridedCursor) next() (Pair, bool) {
+	pair, ok := c.run()
+	for ok && c.off == 0 && c.k < len(c.ranges) && c.ranges[c.k].Lo == pair.Hi+1 {
+		var q Pair
+		q, _ = c.run()
+		pair.Hi = q.Hi
+	}
+	return pair, ok
+}
+
+// combine sweeps the boundaries of the pairs of s and other, which is nil
+// for opComplement, keeping each stretch of runes which op selects.
+func (s *StridedSet) combine(other *StridedSet, op setOp) *StridedSet {
+	a := stridedCursor{ranges: s.ranges}
+	b := stridedCursor{}
+	if other != nil {
+		b.ranges = other.ranges
+	}
+	pa, okA := a.next()
+	pb, okB := b.next()
+
+	var out []StridedRange
+	var pending Pair
+	hasPending := false
+	for lo := rune(0); ; {
+		for okA && pa.Hi < lo {
+			pa, okA = a.next()
+		}
+		for okB && pb.Hi < lo {
+			pb, okB = b.next()
+		}
+		inA := okA && pa.Lo <= lo
+		inB := okB && pb.Lo <= lo
+		hi := rune(unicode.MaxRune)
+		switch {
+		case inA:
+			hi = min(hi, pa.Hi)
+		case okA:
+			hi = min(hi, pa.Lo-1)
+		}
+		switch {
+		case inB:
+			hi = min(hi, pb.Hi)
+		case okB:
+			hi = min(hi, pb.Lo-1)
+		}
+
+		var keep bool
+		switch op {
+		case opUnion:
+			keep = inA || inB
+		case opIntersect:
+			keep = inA && inB
+		case opDifference:
+			keep = inA && !inB
+		case opSymmetricDifference:
+			keep = inA != inB
+		case opComplement:
+			keep = !inA
+		default:
+			panic(fmt.Errorf("BUG: unknown StridedSet operation %d", op))
+		}
+		if keep {
+			if hasPending && pending.Hi+1 == lo {
+				pending.Hi = hi
+			} else {
+				if hasPending {
+					out = appendStrided(out, pending)
+				}
+				pending, hasPending = Pair{lo, hi}, true
+			}
+		}
+
+		if hi >= unicode.MaxRune {
+			break
+		}
+		lo = hi + 1
+	}
+	if hasPending {
+		out = appendStrided(out, pending)
+	}
+	return newStridedSet(out)
+}
+
+// Set returns the Set of runes in the StridedSet, as a newly built list of
+// its pairs.
+func (s *StridedSet) Set() Set {
+	list := make([]Pair, 0, s.Len())
+	c := stridedCursor{ranges: s.ranges}
+	for pair, ok := c.next(); ok; pair, ok = c.next() {
+		list = append(list, pair)
+	}
+	return makeSet(list)
+}
+
+func (s *StridedSet) Count() uint {
+	var sum uint
+	for _, r := range s.ranges {
+		sum += uint(r.count())
+	}
+	return sum
+}
+
+func (s *StridedSet) Union(other *StridedSet) *StridedSet {
+	return s.combine(other, opUnion)
+}
+
+func (s *StridedSet) Intersect(other *StridedSet) *StridedSet {
+	return s.combine(other, opIntersect)
+}
+
+func (s *StridedSet) Difference(other *StridedSet) *StridedSet {
+	return s.combine(other, opDifference)
+}
+
+func (s *StridedSet) SymmetricDifference(other *StridedSet) *StridedSet {
+	return s.combine(other, opSymmetricDifference)
+}
+
+func (s *StridedSet) Complement() *StridedSet {
+	return s.combine(nil, opComplement)
+}
+
+func (s *StridedSet) Len() uint {
+	if len(s.starts) <= 0 {
+		return 0
+	}
+	return uint(s.starts[len(s.ranges)])
+}
+
+func (s *StridedSet) At(index uint) Pair {
+	n := s.Len()
+	if index >= n {
+		panic(fmt.Errorf("index out of range: %d >= %d", index, n))
+	}
+	// find the last range whose first member is in a pair at or before
+	// index; the pair at index ends within that range
+	k := sort.Search(len(s.ranges), func(k int) bool {
+		return uint(s.starts[k]) > index
+	}) - 1
+	r := s.ranges[k]
+	var pair Pair
+	if r.Stride == 1 {
+		pair = Pair{r.Lo, r.Hi}
+	} else {
+		ch := r.Lo + rune((uint32(index)-s.starts[k])*r.Stride)
+		pair = Pair{ch, ch}
+	}
+	// if the pair begins in an earlier range, extend it backward
+	for pair.Lo == r.Lo && k > 0 && s.ranges[k-1].Hi+1 == r.Lo {
+		k--
+		r = s.ranges[k]
+		pair.Lo = r.Hi
+		if r.Stride == 1 {
+			pair.Lo = r.Lo
+		}
+	}
+	return pair
+}
+
+func (s *StridedSet) Search(ch rune) (uint, bool) {
+	k := s.find(ch)
+	if k >= len(s.ranges) {
+		return s.Len(), false
+	}
+	r := s.ranges[k]
+	index := uint(s.starts[k])
+	switch {
+	case ch < r.Lo:
+		return index, false
+	case r.Stride == 1:
+		return index, true
+	}
+	q, rem := uint32(ch-r.Lo)/r.Stride, uint32(ch-r.Lo)%r.Stride
+	if rem != 0 {
+		return index + uint(q) + 1, false
+	}
+	return index + uint(q), true
+}
+
+// find returns the index of the first range whose Hi is at least ch.
+func (s *StridedSet) find(ch rune) int {
+	i, _ := slices.BinarySearchFunc(s.ranges, ch, func(r StridedRange, ch rune) int {
+		switch {
+		case r.Hi < ch:
+			return -1
+		case r.Lo > ch:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return i
+}
+
+func (s *StridedSet) Contains(ch rune) bool {
+	i := s.find(ch)
+	return i < len(s.ranges) && s.ranges[i].contains(ch)
+}
+
+func (s *StridedSet) IsEmpty() bool {
+	return len(s.ranges) <= 0
+}
+
+func (s *StridedSet) IsFull() bool {
+	return isFull(s)
+}
+
+func (s *StridedSet) Append(out []byte) []byte {
+	return appendSource(out, s)
+}
+
+func (s *StridedSet) String() string {
+	return toString(s)
+}
+
+func (*StridedSet) canonical() {}
+
+var (
+	_ Source          = (*StridedSet)(nil)
+	_ alwaysCanonical = (*StridedSet)(nil)
+	_ Appender        = (*StridedSet)(nil)
+	_ fmt.Stringer    = (*StridedSet)(nil)
+)
@@ -0,0 +1,107 @@
+package runeset
+
+import (
+	"math/rand"
+	"testing"
+	"unicode"
+)
+
+func TestStridedSet(t *testing.T) {
+	tables := map[string]*unicode.RangeTable{
+		"Upper": unicode.Upper,
+		"Lower": unicode.Lower,
+		"Title": unicode.Title,
+		"Greek": unicode.Greek,
+		"Nd":    unicode.Nd,
+	}
+	for name, table := range tables {
+		expect := ForTable(table)
+		s := ForTableStrided(table)
+		if len(s.Ranges()) != len(table.R16)+len(table.R32) {
+			t.Errorf("%s: expected %d ranges, got %d", name, len(table.R16)+len(table.R32), len(s.Ranges()))
+		}
+		if !Equal(s, expect) || s.Count() != expect.Count() {
+			t.Errorf("%s: ForTableStrided is not equal to ForTable", name)
+		}
+		for ch := rune(0); ch <= 0x20000; ch++ {
+			if s.Contains(ch) != expect.Contains(ch) {
+				t.Fatalf("%s: Contains(%U): expect %v", name, ch, expect.Contains(ch))
+			}
+		}
+		checkStridedIndex(t, name, s, expect)
+		if !Equal(NewStridedSet(expect), expect) || !Equal(ForTable(s.RangeTable()), expect) {
+			t.Errorf("%s: round trip through NewStridedSet or RangeTable changed the set", name)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(11))
+	randomStrided := func() *StridedSet {
+		var b Builder
+		b.Reset()
+		for i := rng.Intn(6); i > 0; i-- {
+			lo := 0xfff0 + rune(rng.Intn(0x20))
+			stride := 1 + rune(rng.Intn(3))
+			for ch, n := lo, rng.Intn(8); n > 0; ch, n = ch+stride, n-1 {
+				b.AddRune(ch)
+			}
+		}
+		return NewStridedSet(&b)
+	}
+	for iter := 0; iter < 1000; iter++ {
+		x, y := randomStrided(), randomStrided()
+		a, b := x.Set(), y.Set()
+		if !Equal(x.Union(y), a.Union(b)) || !Equal(x.Intersect(y), a.Intersect(b)) ||
+			!Equal(x.Difference(y), a.Difference(b)) || !Equal(x.SymmetricDifference(y), a.SymmetricDifference(b)) ||
+			!Equal(x.Complement(), a.Complement()) {
+			t.Fatalf("algebra on %v, %v differs from Set", x, y)
+		}
+		checkStridedIndex(t, x.String(), x, a)
+		checkStridedIndex(t, "Union", x.Union(y), a.Union(b))
+		checkStridedIndex(t, "Complement", x.Complement(), a.Complement())
+		if !Equal(ForTable(x.RangeTable()), a) || !Equal(ForTableStrided(x.RangeTable()), a) {
+			t.Fatalf("%v: RangeTable round trip: got %v", x, ForTable(x.RangeTable()))
+		}
+	}
+
+	// ranges whose ends are adjacent share a pair
+	joined := ForTableStrided(&unicode.RangeTable{R16: []unicode.Range16{
+		{Lo: 0x41, Hi: 0x45, Stride: 2},
+		{Lo: 0x46, Hi: 0x48, Stride: 1},
+		{Lo: 0x49, Hi: 0x49, Stride: 1},
+		{Lo: 0x4a, Hi: 0x4e, Stride: 2},
+	}})
+	if actual, expect := joined.String(), `[ACE-JLN]`; actual != expect || len(joined.Ranges()) != 4 {
+		t.Errorf("joined ranges: expect %q in 4 ranges, got %q in %d", expect, actual, len(joined.Ranges()))
+	}
+	checkStridedIndex(t, "joined", joined, Make(joined))
+	if allocs := testing.AllocsPerRun(10, func() { joined.At(joined.Len() - 1) }); allocs != 0 {
+		t.Errorf("joined: At allocated %v times", allocs)
+	}
+	if !NewStridedSet(Full()).IsFull() || !ForTableStrided(nil).Complement().IsFull() {
+		t.Errorf("IsFull: complement of the empty StridedSet is not full")
+	}
+}
+
+// checkStridedIndex compares the pairs and search results of s, which are
+// computed from its ranges, against expect.
+func checkStridedIndex(t *testing.T, name string, s *StridedSet, expect Set) {
+	t.Helper()
+	if s.Len() != expect.Len() {
+		t.Fatalf("%s: Len: expect %d, got %d", name, expect.Len(), s.Len())
+	}
+	for i := uint(0); i < expect.Len(); i++ {
+		if actual, expect := s.At(i), expect.At(i); actual != expect {
+			t.Fatalf("%s: At(%d): expect %v, got %v", name, i, expect, actual)
+		}
+	}
+	expect.EachPair(func(pair Pair) bool {
+		for _, ch := range []rune{pair.Lo - 1, pair.Lo, pair.Lo + 1, pair.Hi, pair.Hi + 1} {
+			i, found := s.Search(ch)
+			j, expectFound := expect.Search(ch)
+			if i != j || found != expectFound {
+				t.Fatalf("%s: Search(%U): expect %d, %v, got %d, %v", name, ch, j, expectFound, i, found)
+			}
+		}
+		return true
+	})
+}
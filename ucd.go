@@ -0,0 +1,285 @@
+package runeset
+
+import (
+	"unicode"
+)
+
+// Long names of the General_Category values, from PropertyValueAliases.txt.
+var categoryLongNames = [...][2]string{
+	{"C", "Other"},
+	{"Cc", "Control"},
+	{"Cf", "Format"},
+	{"Cn", "Unassigned"},
+	{"Co", "Private_Use"},
+	{"Cs", "Surrogate"},
+	{"LC", "Cased_Letter"},
+	{"Ll", "Lowercase_Letter"},
+	{"Lm", "Modifier_Letter"},
+	{"Lo", "Other_Letter"},
+	{"Lt", "Titlecase_Letter"},
+	{"Lu", "Uppercase_Letter"},
+	{"M", "Combining_Mark"},
+	{"Mc", "Spacing_Mark"},
+	{"Me", "Enclosing_Mark"},
+	{"Mn", "Nonspacing_Mark"},
+	{"Nd", "Decimal_Number"},
+	{"Nl", "Letter_Number"},
+	{"No", "Other_Number"},
+	{"P", "Punctuation"},
+	{"Pc", "Connector_Punctuation"},
+	{"Pd", "Dash_Punctuation"},
+	{"Pe", "Close_Punctuation"},
+	{"Pf", "Final_Punctuation"},
+	{"Pi", "Initial_Punctuation"},
+	{"Po", "Other_Punctuation"},
+	{"Ps", "Open_Punctuation"},
+	{"Sc", "Currency_Symbol"},
+	{"Sk", "Modifier_Symbol"},
+	{"Sm", "Math_Symbol"},
+	{"So", "Other_Symbol"},
+	{"Z", "Separator"},
+	{"Zl", "Line_Separator"},
+	{"Zp", "Paragraph_Separator"},
+	{"Zs", "Space_Separator"},
+}
+
+// Long names and aliases of General_Category values that coincide with
+// existing class names.  These are registered elsewhere as classes; here they
+// are only made available as "gc=..." values.
+var categoryExistingNames = [...][2]string{
+	{"Cc", "cntrl"},
+	{"L", "Letter"},
+	{"M", "Mark"},
+	{"N", "Number"},
+	{"Nd", "digit"},
+	{"P", "punct"},
+	{"S", "Symbol"},
+}
+
+// ISO 15924 codes of the Script values, from PropertyValueAliases.txt.
+var scriptCodes = [...][2]string{
+	{"Adlm", "Adlam"},
+	{"Aghb", "Caucasian_Albanian"},
+	{"Ahom", "Ahom"},
+	{"Arab", "Arabic"},
+	{"Armi", "Imperial_Aramaic"},
+	{"Armn", "Armenian"},
+	{"Avst", "Avestan"},
+	{"Bali", "Balinese"},
+	{"Bamu", "Bamum"},
+	{"Bass", "Bassa_Vah"},
+	{"Batk", "Batak"},
+	{"Beng", "Bengali"},
+	{"Berf", "Beria_Erfe"},
+	{"Bhks", "Bhaiksuki"},
+	{"Bopo", "Bopomofo"},
+	{"Brah", "Brahmi"},
+	{"Brai", "Braille"},
+	{"Bugi", "Buginese"},
+	{"Buhd", "Buhid"},
+	{"Cakm", "Chakma"},
+	{"Cans", "Canadian_Aboriginal"},
+	{"Cari", "Carian"},
+	{"Cham", "Cham"},
+	{"Cher", "Cherokee"},
+	{"Chrs", "Chorasmian"},
+	{"Copt", "Coptic"},
+	{"Cpmn", "Cypro_Minoan"},
+	{"Cprt", "Cypriot"},
+	{"Cyrl", "Cyrillic"},
+	{"Deva", "Devanagari"},
+	{"Diak", "Dives_Akuru"},
+	{"Dogr", "Dogra"},
+	{"Dsrt", "Deseret"},
+	{"Dupl", "Duployan"},
+	{"Egyp", "Egyptian_Hieroglyphs"},
+	{"Elba", "Elbasan"},
+	{"Elym", "Elymaic"},
+	{"Ethi", "Ethiopic"},
+	{"Gara", "Garay"},
+	{"Geor", "Georgian"},
+	{"Glag", "Glagolitic"},
+	{"Gong", "Gunjala_Gondi"},
+	{"Gonm", "Masaram_Gondi"},
+	{"Goth", "Gothic"},
+	{"Gran", "Grantha"},
+	{"Grek", "Greek"},
+	{"Gujr", "Gujarati"},
+	{"Gukh", "Gurung_Khema"},
+	{"Guru", "Gurmukhi"},
+	{"Hang", "Hangul"},
+	{"Hani", "Han"},
+	{"Hano", "Hanunoo"},
+	{"Hatr", "Hatran"},
+	{"Hebr", "Hebrew"},
+	{"Hira", "Hiragana"},
+	{"Hluw", "Anatolian_Hieroglyphs"},
+	{"Hmng", "Pahawh_Hmong"},
+	{"Hmnp", "Nyiakeng_Puachue_Hmong"},
+	{"Hung", "Old_Hungarian"},
+	{"Ital", "Old_Italic"},
+	{"Java", "Javanese"},
+	{"Kali", "Kayah_Li"},
+	{"Kana", "Katakana"},
+	{"Kawi", "Kawi"},
+	{"Khar", "Kharoshthi"},
+	{"Khmr", "Khmer"},
+	{"Khoj", "Khojki"},
+	{"Kits", "Khitan_Small_Script"},
+	{"Knda", "Kannada"},
+	{"Krai", "Kirat_Rai"},
+	{"Kthi", "Kaithi"},
+	{"Lana", "Tai_Tham"},
+	{"Laoo", "Lao"},
+	{"Latn", "Latin"},
+	{"Lepc", "Lepcha"},
+	{"Limb", "Limbu"},
+	{"Lina", "Linear_A"},
+	{"Linb", "Linear_B"},
+	{"Lisu", "Lisu"},
+	{"Lyci", "Lycian"},
+	{"Lydi", "Lydian"},
+	{"Mahj", "Mahajani"},
+	{"Maka", "Makasar"},
+	{"Mand", "Mandaic"},
+	{"Mani", "Manichaean"},
+	{"Marc", "Marchen"},
+	{"Medf", "Medefaidrin"},
+	{"Mend", "Mende_Kikakui"},
+	{"Merc", "Meroitic_Cursive"},
+	{"Mero", "Meroitic_Hieroglyphs"},
+	{"Mlym", "Malayalam"},
+	{"Modi", "Modi"},
+	{"Mong", "Mongolian"},
+	{"Mroo", "Mro"},
+	{"Mtei", "Meetei_Mayek"},
+	{"Mult", "Multani"},
+	{"Mymr", "Myanmar"},
+	{"Nagm", "Nag_Mundari"},
+	{"Nand", "Nandinagari"},
+	{"Narb", "Old_North_Arabian"},
+	{"Nbat", "Nabataean"},
+	{"Newa", "Newa"},
+	{"Nkoo", "Nko"},
+	{"Nshu", "Nushu"},
+	{"Ogam", "Ogham"},
+	{"Olck", "Ol_Chiki"},
+	{"Onao", "Ol_Onal"},
+	{"Orkh", "Old_Turkic"},
+	{"Orya", "Oriya"},
+	{"Osge", "Osage"},
+	{"Osma", "Osmanya"},
+	{"Ougr", "Old_Uyghur"},
+	{"Palm", "Palmyrene"},
+	{"Pauc", "Pau_Cin_Hau"},
+	{"Perm", "Old_Permic"},
+	{"Phag", "Phags_Pa"},
+	{"Phli", "Inscriptional_Pahlavi"},
+	{"Phlp", "Psalter_Pahlavi"},
+	{"Phnx", "Phoenician"},
+	{"Plrd", "Miao"},
+	{"Prti", "Inscriptional_Parthian"},
+	{"Qaac", "Coptic"},
+	{"Qaai", "Inherited"},
+	{"Rjng", "Rejang"},
+	{"Rohg", "Hanifi_Rohingya"},
+	{"Runr", "Runic"},
+	{"Samr", "Samaritan"},
+	{"Sarb", "Old_South_Arabian"},
+	{"Saur", "Saurashtra"},
+	{"Sgnw", "SignWriting"},
+	{"Shaw", "Shavian"},
+	{"Shrd", "Sharada"},
+	{"Sidd", "Siddham"},
+	{"Sidt", "Sidetic"},
+	{"Sind", "Khudawadi"},
+	{"Sinh", "Sinhala"},
+	{"Sogd", "Sogdian"},
+	{"Sogo", "Old_Sogdian"},
+	{"Sora", "Sora_Sompeng"},
+	{"Soyo", "Soyombo"},
+	{"Sund", "Sundanese"},
+	{"Sunu", "Sunuwar"},
+	{"Sylo", "Syloti_Nagri"},
+	{"Syrc", "Syriac"},
+	{"Tagb", "Tagbanwa"},
+	{"Takr", "Takri"},
+	{"Tale", "Tai_Le"},
+	{"Talu", "New_Tai_Lue"},
+	{"Taml", "Tamil"},
+	{"Tang", "Tangut"},
+	{"Tavt", "Tai_Viet"},
+	{"Tayo", "Tai_Yo"},
+	{"Telu", "Telugu"},
+	{"Tfng", "Tifinagh"},
+	{"Tglg", "Tagalog"},
+	{"Thaa", "Thaana"},
+	{"Thai", "Thai"},
+	{"Tibt", "Tibetan"},
+	{"Tirh", "Tirhuta"},
+	{"Tnsa", "Tangsa"},
+	{"Todr", "Todhri"},
+	{"Tols", "Tolong_Siki"},
+	{"Toto", "Toto"},
+	{"Tutg", "Tulu_Tigalari"},
+	{"Ugar", "Ugaritic"},
+	{"Vaii", "Vai"},
+	{"Vith", "Vithkuqi"},
+	{"Wara", "Warang_Citi"},
+	{"Wcho", "Wancho"},
+	{"Xpeo", "Old_Persian"},
+	{"Xsux", "Cuneiform"},
+	{"Yezi", "Yezidi"},
+	{"Yiii", "Yi"},
+	{"Zanb", "Zanabazar_Square"},
+	{"Zinh", "Inherited"},
+	{"Zyyy", "Common"},
+}
+
+var (
+	categoryProps = []string{"General_Category", "gc"}
+	scriptProps   = []string{"Script", "sc"}
+)
+
+// registerUnicode adds the stdlib's category, script and property tables,
+// plus their UCD aliases, to r.
+func registerUnicode(r *Registry) {
+	for name, table := range unicode.Categories {
+		r.registerLazy(name, forTableFunc(table))
+		r.registerValue(categoryProps, name, name)
+	}
+	for _, row := range categoryLongNames {
+		short, long := row[0], row[1]
+		if _, found := unicode.Categories[short]; !found {
+			continue
+		}
+		r.MustRegisterAlias(long, short)
+		r.registerValue(categoryProps, long, short)
+	}
+	for _, row := range categoryExistingNames {
+		r.registerValue(categoryProps, row[1], row[0])
+	}
+
+	for name, table := range unicode.Scripts {
+		r.registerLazy(name, forTableFunc(table))
+		r.registerValue(scriptProps, name, name)
+	}
+	for _, row := range scriptCodes {
+		code, name := row[0], row[1]
+		if _, found := unicode.Scripts[name]; !found {
+			continue
+		}
+		if looseName(code) != looseName(name) {
+			r.MustRegisterAlias(code, name)
+		}
+		r.registerValue(scriptProps, code, name)
+	}
+
+	for name, table := range unicode.Properties {
+		r.registerLazy(name, forTableFunc(table))
+	}
+}
+
+func forTableFunc(table *unicode.RangeTable) func() Set {
+	return func() Set { return ForTable(table) }
+}
@@ -32,101 +32,21 @@ func cloneList[T any](in []T) []T {
 	return out
 }
 
-func cloneListInto[T any](storage []T, in []T) []T {
-	if len(in) <= 0 {
-		return nil
-	}
-	if len(in) <= cap(storage) {
-		return append(storage[:0], in...)
-	}
-	out := make([]T, len(in))
-	copy(out, in)
-	return out
-}
-
-func splice[T any](in []T, i uint, j uint, insert ...T) []T {
-	inLen := uint(len(in))
-	inCap := uint(cap(in))
-	if i > j {
-		panic(fmt.Errorf("BUG: i > j [i=%d j=%d n=%d]", i, j, inLen))
-	}
-	if j > inLen {
-		panic(fmt.Errorf("BUG: j > n [i=%d j=%d n=%d]", i, j, inLen))
-	}
-	head := in[:i]
-	tail := in[j:]
-	headLen := i
-	deleteLen := j - i
-	insertLen := uint(len(insert))
-	tailLen := inLen - j
-	outLen := headLen + insertLen + tailLen
-	out := head
-	switch {
-	case outLen > inCap:
-		out = make([]T, headLen, outLen)
-		copy(out, head)
-	case insertLen > deleteLen && tailLen > 0:
-		tmp := make([]T, tailLen)
-		copy(tmp, tail)
-		tail = tmp
-	}
-	out = append(out, insert...)
-	out = append(out, tail...)
-	return out
-}
-
-// segment divides p into 3 or fewer pieces:
-//
-// - the part of p that lies strictly before q
-//
-// - the part of p that overlaps q
-//
-// - the part of p that lies strictly after q
-func segment(p Pair, q Pair, x, y, z func(Pair)) {
-	p.AssertValid()
-	q.AssertValid()
-
-	a, b := p.Lo, p.Hi
-	c, d := q.Lo, q.Hi
-
-	dummy := func(Pair) {}
-	if x == nil {
-		x = dummy
-	}
-	if y == nil {
-		y = dummy
-	}
-	if z == nil {
-		z = dummy
-	}
-
-	// Of the 24 permutations
-	// 18 are eliminated by a <= b && c <= d
-	// leaving these 6 cases to handle:
-	switch {
-	case b < c:
-		// a b c d
-		x(Pair{a, b})
-	case a < c && d >= b:
-		// a c b d
-		x(Pair{a, c - 1})
-		y(Pair{c, b})
-	case a < c:
-		// a c d b
-		x(Pair{a, c - 1})
-		y(Pair{c, d})
-		z(Pair{d + 1, b})
-	case d < a:
-		// c d a b
-		z(Pair{a, b})
-	case d < b:
-		// c a d b
-		y(Pair{a, d})
-		z(Pair{d + 1, b})
-	default:
-		// c a b d
-		y(Pair{a, b})
+// sourceAt is like src.At(index), except that it returns invalid Pair and
+// Rune sources as-is instead of panicking, so that callers can validate them.
+func sourceAt[S Source](src S, index uint) Pair {
+	switch x := any(src).(type) {
+	case Pair:
+		if index == 0 {
+			return x
+		}
+	case Rune:
+		if index == 0 {
+			ch := rune(x)
+			return Pair{ch, ch}
+		}
 	}
+	return src.At(index)
 }
 
 func isEmpty[S Source](src S) bool {
@@ -144,6 +64,12 @@ func isFull[S Source](src S) bool {
 }
 
 func appendSource[S Source](out []byte, src S) []byte {
+	return appendBracketed(out, src, appendRune)
+}
+
+// appendBracketed appends src in bracket notation, using appendCh to write
+// the bounds of each pair.
+func appendBracketed[S Source](out []byte, src S, appendCh func([]byte, rune) []byte) []byte {
 	switch {
 	case isEmpty(src):
 		out = append(out, '!', '.')
@@ -156,24 +82,18 @@ func appendSource[S Source](out []byte, src S) []byte {
 		out = append(out, '[')
 		for i := uint(0); i < srcLen; i++ {
 			p := src.At(i)
-			out = appendPair(out, p.Lo, p.Hi)
+			out = appendCh(out, p.Lo)
+			if p.Lo != p.Hi {
+				out = append(out, '-')
+				out = appendCh(out, p.Hi)
+			}
 		}
 		out = append(out, ']')
 	}
 	return out
 }
 
-func appendPair(out []byte, lo rune, hi rune) []byte {
-	out = appendRune(out, lo)
-	if lo != hi {
-		out = append(out, '-')
-		out = appendRune(out, hi)
-	}
-	return out
-}
-
 func appendRune(out []byte, ch rune) []byte {
-	u32 := uint32(ch)
 	switch {
 	case ch >= '0' && ch <= '9':
 		fallthrough
@@ -185,7 +105,29 @@ func appendRune(out []byte, ch rune) []byte {
 		fallthrough
 	case unicode.IsDigit(ch):
 		return utf8.AppendRune(out, ch)
+	default:
+		return appendEscape(out, ch)
+	}
+}
 
+// appendASCIIRune is like appendRune, but escapes everything except ASCII
+// letters and digits.
+func appendASCIIRune(out []byte, ch rune) []byte {
+	switch {
+	case ch >= '0' && ch <= '9':
+		fallthrough
+	case ch >= 'A' && ch <= 'Z':
+		fallthrough
+	case ch >= 'a' && ch <= 'z':
+		return append(out, byte(ch))
+	default:
+		return appendEscape(out, ch)
+	}
+}
+
+func appendEscape(out []byte, ch rune) []byte {
+	u32 := uint32(ch)
+	switch {
 	case ch == 0:
 		return append(out, '\\', '0')
 	case ch == '\t':
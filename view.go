@@ -0,0 +1,138 @@
+package runeset
+
+import (
+	"fmt"
+	"sync"
+)
+
+// View is a lazily evaluated combination of Sources.  Contains is answered
+// directly from the operands, without materializing anything.  The first
+// call to Len or At computes the canonical pairs of the View, which are then
+// cached.  Operands must not change after the View is created.
+//
+// Operands which are not canonical, such as a PairList, are validated and
+// normalized when the View is created, so that Contains and At agree.  As
+// with Make, an invalid pair panics.
+type View struct {
+	op   setOp
+	a    Source
+	b    Source
+	once sync.Once
+	list []Pair
+	info *setInfo
+}
+
+func UnionOf(a Source, b Source) *View {
+	return newView(opUnion, a, b)
+}
+
+func IntersectionOf(a Source, b Source) *View {
+	return newView(opIntersect, a, b)
+}
+
+func DifferenceOf(a Source, b Source) *View {
+	return newView(opDifference, a, b)
+}
+
+func SymmetricDifferenceOf(a Source, b Source) *View {
+	return newView(opSymmetricDifference, a, b)
+}
+
+func ComplementOf(a Source) *View {
+	return newView(opComplement, a, nil)
+}
+
+func newView(op setOp, a Source, b Source) *View {
+	v := &View{op: op, a: canonicalSource(a)}
+	if b != nil {
+		v.b = canonicalSource(b)
+	}
+	return v
+}
+
+func (v *View) pairs() []Pair {
+	v.once.Do(func() {
+		a, b := v.a, v.b
+		var out []Pair
+		if v.op == opComplement {
+			out = complementSweep(make([]Pair, 0, a.Len()+1), a)
+		} else {
+			out = make([]Pair, 0, a.Len()+b.Len())
+			switch v.op {
+			case opUnion:
+				out = unionSweep(out, a, b)
+			case opIntersect:
+				out = intersectSweep(out, a, b)
+			case opDifference:
+				out = differenceSweep(out, a, b)
+			case opSymmetricDifference:
+				out = symmetricSweep(out, a, b)
+			}
+		}
+		set := makeSet(out)
+		v.list, v.info = set.list, set.info
+	})
+	return v.list
+}
+
+func (v *View) Len() uint {
+	return uint(len(v.pairs()))
+}
+
+func (v *View) At(index uint) Pair {
+	return v.pairs()[index]
+}
+
+func (v *View) Search(ch rune) (uint, bool) {
+	return searchSource(v, ch)
+}
+
+func (v *View) Contains(ch rune) bool {
+	if !isValidRune(ch) {
+		return false
+	}
+	switch v.op {
+	case opUnion:
+		return v.a.Contains(ch) || v.b.Contains(ch)
+	case opIntersect:
+		return v.a.Contains(ch) && v.b.Contains(ch)
+	case opDifference:
+		return v.a.Contains(ch) && !v.b.Contains(ch)
+	case opSymmetricDifference:
+		return v.a.Contains(ch) != v.b.Contains(ch)
+	case opComplement:
+		return !v.a.Contains(ch)
+	default:
+		panic(fmt.Errorf("BUG: unknown View operation %d", v.op))
+	}
+}
+
+func (v *View) IsEmpty() bool {
+	return isEmpty(v)
+}
+
+func (v *View) IsFull() bool {
+	return isFull(v)
+}
+
+func (v *View) Append(out []byte) []byte {
+	return appendSource(out, v)
+}
+
+func (v *View) String() string {
+	return toString(v)
+}
+
+// Set materializes the View.  The Set shares the View's cached pairs.
+func (v *View) Set() Set {
+	return Set{list: v.pairs(), info: v.info}
+}
+
+func (*View) canonical() {}
+
+var (
+	_ Source          = (*View)(nil)
+	_ alwaysCanonical = (*View)(nil)
+	_ Appender        = (*View)(nil)
+	_ fmt.Stringer    = (*View)(nil)
+)
@@ -0,0 +1,86 @@
+package runeset
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"unicode"
+)
+
+func TestViews(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	for iter := 0; iter < 1000; iter++ {
+		x := randomPairs(rng)
+		y := Make(randomPairs(rng))
+		z := randomPairs(rng)
+		mx, my, mz := modelOf(x), modelOf(y), modelOf(z)
+
+		// (x ∪ y) -- (z △ x), and its complement
+		inner := SymmetricDifferenceOf(z, x)
+		view := DifferenceOf(UnionOf(x, y), inner)
+		complement := ComplementOf(view)
+		expect := make(modelSet)
+		for ch := rune(0); ch < modelDomain; ch++ {
+			if (mx[ch] || my[ch]) && mz[ch] == mx[ch] {
+				expect[ch] = true
+			}
+		}
+		if ch := rune(unicode.MaxRune); (mx[ch] || my[ch]) && mz[ch] == mx[ch] {
+			expect[ch] = true
+		}
+
+		for ch := rune(0); ch < modelDomain; ch++ {
+			if view.Contains(ch) != expect[ch] {
+				t.Fatalf("%v: Contains(%q): expect %v", view, ch, expect[ch])
+			}
+			if complement.Contains(ch) == expect[ch] {
+				t.Fatalf("%v: Contains(%q): expect %v", complement, ch, !expect[ch])
+			}
+		}
+		if actual := view.String(); actual != expect.String() {
+			t.Fatalf("wrong view:\n\texpect: %v\n\tactual: %v", expect, actual)
+		}
+		if actual, expect := complement.Set().String(), view.Set().Complement().String(); actual != expect {
+			t.Fatalf("wrong complement:\n\texpect: %v\n\tactual: %v", expect, actual)
+		}
+	}
+}
+
+func TestViews_Lazy(t *testing.T) {
+	// each run needs a View that has not been materialized yet
+	const runs = 100
+	views := make([]*View, runs+1)
+	for i := range views {
+		views[i] = ComplementOf(DifferenceOf(UnionOf(ForClass("L"), ForClass("N")), ForClass("ascii")))
+	}
+	next := 0
+	allocs := testing.AllocsPerRun(runs, func() {
+		view := views[next]
+		next++
+		if view.Contains(0xe9) || !view.Contains('1') {
+			t.Fatal("wrong result")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Contains materialized the view: %v allocations", allocs)
+	}
+}
+
+func TestViews_Operands(t *testing.T) {
+	view := UnionOf(PairList{{'x', 'z'}, {'a', 'c'}, {'b', 'f'}}, RuneList{'q'})
+	if actual, expect := view.String(), `[a-fqx-z]`; actual != expect {
+		t.Errorf("wrong view:\n\texpect: %v\n\tactual: %v", expect, actual)
+	}
+	for ch := rune('a'); ch <= 'z'; ch++ {
+		if _, expect := view.Search(ch); view.Contains(ch) != expect {
+			t.Errorf("%v: Contains(%q) disagrees with the pairs", view, ch)
+		}
+	}
+
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, ErrInvalidRune) {
+			t.Errorf("UnionOf with an invalid pair: expected a panic with ErrInvalidRune, got %v", err)
+		}
+	}()
+	UnionOf(PairList{{0x10fff0, 0x200000}}, Empty())
+}